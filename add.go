@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bennett-matt/goth-generator/generator"
+)
+
+// runAdd implements `goth-generate add <module>`.
+func runAdd(args []string) int {
+	fs := newFlagSet("add")
+	dir := fs.String("dir", ".", "Project directory")
+	dryRun := fs.Bool("dry-run", false, "Report what would change without writing anything")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	feature := fs.Arg(0)
+	if !slices.Contains(generator.Features, feature) {
+		fmt.Fprintf(os.Stderr, "Error: unknown module %q (want one of %s)\n", feature, strings.Join(generator.Features, ", "))
+		return exitUsage
+	}
+
+	m, err := generator.LoadManifest(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v (add needs the project's %s)\n", err, generator.ManifestFile)
+		return exitError
+	}
+	if generator.HasFeature(&m.Config, feature) {
+		fmt.Printf("✅ %s is already enabled\n", feature)
+		return exitOK
+	}
+
+	results, err := generator.Add(generator.DirFS(*dir), m, feature, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	conflicts, skipped := 0, 0
+	for _, r := range results {
+		switch r.Status {
+		case generator.UpgradeUnchanged, generator.UpgradeKept:
+			continue
+		case generator.UpgradeConflict:
+			conflicts++
+			fmt.Printf("  %-9s %s (%d conflicting region(s))\n", r.Status, r.Path, r.Conflicts)
+		case generator.UpgradeSkipped:
+			skipped++
+			fmt.Printf("  %-9s %s (%s)\n", r.Status, r.Path, r.Note)
+		default:
+			fmt.Printf("  %-9s %s\n", r.Status, r.Path)
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("🔍 Dry run: nothing was written (%d file(s) would conflict)\n", conflicts)
+		return exitOK
+	}
+	if conflicts > 0 {
+		fmt.Printf("⚠️  %d file(s) have conflict markers (<<<<<<< project ... >>>>>>> generated with %s); resolve them and commit\n", conflicts, feature)
+		return exitError
+	}
+	fmt.Printf("✅ Added %s\n", feature)
+	if skipped > 0 {
+		fmt.Printf("⚠️  Review the skipped files above; they may need the change by hand\n")
+	}
+	fmt.Printf("🚀 Next steps:\n")
+	fmt.Printf("   1. make sqlc templ\n")
+	fmt.Printf("   2. make migrate-up\n")
+	return exitOK
+}
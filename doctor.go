@@ -0,0 +1,249 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bennett-matt/goth-generator/generator"
+	"golang.org/x/mod/modfile"
+)
+
+// checkStatus is the outcome of one doctor check.
+type checkStatus string
+
+const (
+	checkOK   checkStatus = "ok"
+	checkWarn checkStatus = "warn" // missing or too old, but not needed for this project
+	checkFail checkStatus = "fail"
+	checkSkip checkStatus = "skip" // does not apply here
+)
+
+// doctorCheck is one line of the doctor report.
+type doctorCheck struct {
+	Name     string      `json:"name"`
+	Status   checkStatus `json:"status"`
+	Version  string      `json:"version,omitempty"`
+	Minimum  string      `json:"minimum,omitempty"`
+	Required bool        `json:"required"`
+	Message  string      `json:"message"`
+	Hint     string      `json:"hint,omitempty"`
+}
+
+// doctorReport is what `goth-generate doctor -json` prints.
+type doctorReport struct {
+	OK      bool          `json:"ok"`
+	Project string        `json:"project,omitempty"` // directory of the generated project checked, if any
+	Checks  []doctorCheck `json:"checks"`
+}
+
+// tool is a command generated projects use.
+type tool struct {
+	name    string
+	args    []string // print the version
+	env     []string // added to the environment of the version command
+	minimum string
+	purpose string
+	hint    string
+	// required reports whether the tool is needed for project, which is nil
+	// outside a generated project.
+	required func(project *generator.Config) bool
+}
+
+var tools = []tool{
+	{
+		name: "go", args: []string{"version"}, minimum: generator.MinGoVersion,
+		// Report the installed Go, not a toolchain go.mod would switch to.
+		env:      []string{"GOTOOLCHAIN=local"},
+		purpose:  "builds and runs the project",
+		hint:     "install Go from https://go.dev/dl/",
+		required: func(*generator.Config) bool { return true },
+	},
+	{
+		name: "sqlc", args: []string{"version"}, minimum: "1.30.0",
+		purpose:  "regenerates internal/db after changing the schema or queries (make sqlc)",
+		hint:     "go install github.com/sqlc-dev/sqlc/cmd/sqlc@latest",
+		required: func(*generator.Config) bool { return false },
+	},
+	{
+		name: "node", args: []string{"--version"}, minimum: "20.0.0",
+		purpose:  "runs the Tailwind CSS build",
+		hint:     "install Node.js 20 or newer from https://nodejs.org/",
+		required: func(*generator.Config) bool { return true },
+	},
+	{
+		name: "npm", args: []string{"--version"}, minimum: "9.0.0",
+		purpose:  "installs the CSS toolchain (make setup, make dev)",
+		hint:     "npm ships with Node.js; install Node.js from https://nodejs.org/",
+		required: func(*generator.Config) bool { return true },
+	},
+	{
+		name: "npx", args: []string{"--version"}, minimum: "9.0.0",
+		purpose:  "runs the Tailwind CLI (npm run build:css)",
+		hint:     "npx ships with npm; install Node.js from https://nodejs.org/",
+		required: func(*generator.Config) bool { return true },
+	},
+	{
+		name: "perl", args: []string{"-v"}, minimum: "5.10.0",
+		purpose:  "post-processes templ output in make dev, make templ and make setup",
+		hint:     "install perl with your system package manager",
+		required: func(*generator.Config) bool { return true },
+	},
+	{
+		name: "docker", args: []string{"--version"}, minimum: "20.10.0",
+		purpose: "runs the database server for development and builds the image",
+		hint:    "install Docker from https://docs.docker.com/get-docker/",
+		required: func(project *generator.Config) bool {
+			return project == nil || project.DBDriver != "sqlite"
+		},
+	},
+}
+
+// runDoctor implements `goth-generate doctor`.
+func runDoctor(args []string) int {
+	fs := newFlagSet("doctor")
+	dir := fs.String("dir", ".", "Project directory; outside a generated project only the tools are checked")
+	jsonOutput := fs.Bool("json", false, "Print the result as JSON")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	report := doctorReport{OK: true}
+	var project *generator.Config
+	if m, err := generator.LoadManifest(*dir); err == nil {
+		project = &m.Config
+		report.Project = *dir
+	} else if !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	for _, t := range tools {
+		if t.name == "go" && project != nil {
+			// The project builds only with the Go its go.mod asks for,
+			// which may be newer than the generator's floor.
+			t.minimum = projectGoVersion(*dir, project)
+		}
+		report.Checks = append(report.Checks, checkTool(t, project))
+	}
+	report.Checks = append(report.Checks, checkEnvFile(*dir, project))
+	for _, c := range report.Checks {
+		if c.Status == checkFail {
+			report.OK = false
+		}
+	}
+
+	if *jsonOutput {
+		printJSON(report)
+	} else {
+		printDoctor(report)
+	}
+	if !report.OK {
+		return exitError
+	}
+	return exitOK
+}
+
+// projectGoVersion returns the go directive of the project's go.mod,
+// falling back to the Go version its manifest records.
+func projectGoVersion(dir string, project *generator.Config) string {
+	goModPath := filepath.Join(dir, "go.mod")
+	if data, err := os.ReadFile(goModPath); err == nil {
+		if f, err := modfile.ParseLax(goModPath, data, nil); err == nil && f.Go != nil {
+			return f.Go.Version
+		}
+	}
+	if project.GoVersion != "" {
+		return project.GoVersion
+	}
+	return generator.MinGoVersion
+}
+
+// versionRe finds the first dotted version number in a command's output.
+var versionRe = regexp.MustCompile(`\d+(\.\d+)+`)
+
+func checkTool(t tool, project *generator.Config) doctorCheck {
+	c := doctorCheck{Name: t.name, Minimum: t.minimum, Required: t.required(project)}
+	fail := checkWarn
+	if c.Required {
+		fail = checkFail
+	}
+
+	if _, err := exec.LookPath(t.name); err != nil {
+		c.Status, c.Message, c.Hint = fail, "not found; it "+t.purpose, t.hint
+		return c
+	}
+	cmd := exec.Command(t.name, t.args...)
+	cmd.Env = append(os.Environ(), t.env...)
+	out, err := cmd.CombinedOutput()
+	c.Version = versionRe.FindString(string(out))
+	if err != nil || c.Version == "" {
+		c.Status, c.Message, c.Hint = fail, "installed, but its version could not be determined", t.hint
+		return c
+	}
+	if !versionAtLeast(c.Version, t.minimum) {
+		c.Status, c.Message, c.Hint = fail, fmt.Sprintf("version %s is older than %s", c.Version, t.minimum), t.hint
+		return c
+	}
+	c.Status, c.Message = checkOK, t.purpose
+	return c
+}
+
+// checkEnvFile checks that a generated project has the .env file its
+// server and migrations read.
+func checkEnvFile(dir string, project *generator.Config) doctorCheck {
+	c := doctorCheck{Name: ".env", Required: project != nil}
+	if project == nil {
+		c.Status, c.Message = checkSkip, "not in a generated project (no "+generator.ManifestFile+")"
+		return c
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".env")); err != nil {
+		c.Status, c.Message, c.Hint = checkFail, "missing; the server reads DATABASE_URL and SECRET_KEY from it", "cp .env.example .env (make setup does this)"
+		return c
+	}
+	c.Status, c.Message = checkOK, "present"
+	return c
+}
+
+// versionAtLeast reports whether dotted version v is at least min.
+func versionAtLeast(v, min string) bool {
+	vs, ms := strings.Split(v, "."), strings.Split(min, ".")
+	for i := range ms {
+		var x int
+		if i < len(vs) {
+			x, _ = strconv.Atoi(vs[i])
+		}
+		y, _ := strconv.Atoi(ms[i])
+		if x != y {
+			return x > y
+		}
+	}
+	return true
+}
+
+func printDoctor(r doctorReport) {
+	icons := map[checkStatus]string{checkOK: "✅", checkWarn: "⚠️ ", checkFail: "❌", checkSkip: "➖"}
+	if r.Project != "" {
+		fmt.Printf("Checking prerequisites for the project in %s\n\n", r.Project)
+	}
+	for _, c := range r.Checks {
+		version := c.Version
+		if version == "" {
+			version = "-"
+		}
+		fmt.Printf("%s %-7s %-10s %s\n", icons[c.Status], c.Name, version, c.Message)
+		if c.Hint != "" {
+			fmt.Printf("   %-7s %-10s → %s\n", "", "", c.Hint)
+		}
+	}
+	if r.OK {
+		fmt.Printf("\nAll required tools are available.\n")
+	} else {
+		fmt.Printf("\nSome required tools are missing or too old; see the hints above.\n")
+	}
+}
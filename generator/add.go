@@ -0,0 +1,211 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// Features are the modules `goth-generate add` can enable in an existing
+// project.
+var Features = []string{"auth", "sessions", "users"}
+
+// HasFeature reports whether feature is enabled in config.
+func HasFeature(config *Config, feature string) bool {
+	switch feature {
+	case "auth":
+		return config.WithAuth
+	case "sessions":
+		return config.WithSessions
+	case "users":
+		return config.WithUsers
+	}
+	return false
+}
+
+// EnableFeature enables feature in config. Login needs somewhere to keep
+// the session, so auth also enables sessions.
+func EnableFeature(config *Config, feature string) error {
+	switch feature {
+	case "auth":
+		config.WithAuth = true
+		config.WithSessions = true
+	case "sessions":
+		config.WithSessions = true
+	case "users":
+		config.WithUsers = true
+	default:
+		return fmt.Errorf("unknown feature %q (want one of %v)", feature, Features)
+	}
+	return nil
+}
+
+// Add enables feature in the project in fsys, described by its manifest.
+//
+// Generated files are three-way merged the way Upgrade merges them, with
+// the output for the current configuration as the base, so new packages
+// such as internal/session are added and edits to existing files are kept.
+// cmd/server/main.go is edited in place instead: the imports, services,
+// middleware, routes and NewHandler arguments the feature needs are
+// inserted next to the existing ones. The pinned modules the feature needs
+// are added to go.mod and go.sum. Tables the feature needs get a new
+// migration. With dryRun nothing is written.
+func Add(fsys FS, m *Manifest, feature string, dryRun bool) ([]UpgradeFile, error) {
+	if m.GeneratorVersion != Version {
+		return nil, fmt.Errorf("project was generated by goth-generate %s; run `goth-generate upgrade` first", m.GeneratorVersion)
+	}
+	if !slices.Contains(Features, feature) {
+		return nil, fmt.Errorf("unknown feature %q (want one of %v)", feature, Features)
+	}
+
+	config := m.Config
+	if err := EnableFeature(&config, feature); err != nil {
+		return nil, err
+	}
+	base, err := Pristine(m.Config)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := pristineFiles(config)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := ReadLedger(fsys)
+	if err != nil {
+		return nil, err
+	}
+	labels := MergeLabels{
+		Ours:   "project",
+		Base:   "generated without " + feature,
+		Theirs: "generated with " + feature,
+	}
+
+	migrations, err := featureMigrations(fsys, m.Config, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []UpgradeFile
+	writes := map[string][]byte{}
+	for _, f := range append(newFiles, migrations...) {
+		var (
+			res   UpgradeFile
+			write []byte
+		)
+		if _, applied := base[f.Path]; applied && strings.HasPrefix(f.Path, "db/migrations/") {
+			// Applied migrations stay as they are; the feature's tables
+			// come from the new migration.
+			continue
+		}
+		switch f.Path {
+		case ManifestFile, LedgerFile:
+			continue
+		case "go.mod", "go.sum":
+			// The ledger entry is kept up to date by mergeModFile.
+			res, write, err = mergeModFile(fsys, f, ledger, &config)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, res)
+			if write != nil {
+				writes[f.Path] = write
+			}
+			continue
+		case mainGoPath:
+			res, write, err = addToMain(fsys, f, base, ledger, config)
+		default:
+			res, write, err = mergeFile(fsys, f, base, ledger, labels)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+		if res.Status != UpgradeSkipped {
+			ledger[f.Path] = Sum(f.Content)
+		}
+		if write != nil {
+			writes[f.Path] = write
+		}
+	}
+
+	if dryRun {
+		return results, nil
+	}
+	for _, res := range results {
+		if write, ok := writes[res.Path]; ok {
+			if err := fsys.WriteFile(res.Path, write); err != nil {
+				return nil, fmt.Errorf("failed to write %s: %w", res.Path, err)
+			}
+		}
+	}
+	data, err := (&Manifest{Config: config}).Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if err := fsys.WriteFile(ManifestFile, data); err != nil {
+		return nil, err
+	}
+	ledger[ManifestFile] = Sum(data)
+	if err := fsys.WriteFile(LedgerFile, ledger.Marshal()); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+const mainGoPath = "cmd/server/main.go"
+
+// addToMain brings cmd/server/main.go up to config. An untouched file is
+// replaced with the new output; an edited one is changed in place.
+func addToMain(fsys FS, f PlannedFile, base map[string][]byte, ledger Ledger, config Config) (UpgradeFile, []byte, error) {
+	res := UpgradeFile{Path: f.Path}
+	ours, err := fsys.ReadFile(f.Path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		res.Status, res.Note = UpgradeSkipped, "deleted in the project"
+		return res, nil, nil
+	case err != nil:
+		return res, nil, err
+	case bytes.Equal(ours, f.Content):
+		res.Status = UpgradeUnchanged
+		return res, nil, nil
+	case bytes.Equal(ours, base[f.Path]), ledger.Untouched(f.Path, ours):
+		res.Status = UpgradeUpdated
+		return res, f.Content, nil
+	}
+
+	edited, err := editMain(ours, &config)
+	if err != nil {
+		res.Status, res.Note = UpgradeSkipped, err.Error()
+		return res, nil, nil
+	}
+	if bytes.Equal(edited, ours) {
+		res.Status = UpgradeKept
+		return res, nil, nil
+	}
+	res.Status = UpgradeEdited
+	return res, edited, nil
+}
+
+// featureMigrations renders a migration creating the tables enabled in
+// config but not in old.
+func featureMigrations(fsys FS, old, config Config) ([]PlannedFile, error) {
+	if !config.WithSessions || old.WithSessions {
+		return nil, nil
+	}
+	g := New(&config, WithFS(fsys))
+	version, err := g.nextMigrationVersion()
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("db/migrations/%06d_add_sessions", version)
+	if err := g.writeTemplate(name+".up.sql", "add/sessions.up.sql", g.config); err != nil {
+		return nil, err
+	}
+	if err := g.writeTemplate(name+".down.sql", "add/sessions.down.sql", g.config); err != nil {
+		return nil, err
+	}
+	return g.files, nil
+}
@@ -0,0 +1,33 @@
+package generator
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAddKeepsTemplateOverrides(t *testing.T) {
+	dir, marker := overrideMiddleware(t)
+	config := Config{
+		Name:      "app",
+		Module:    "example.com/app",
+		Port:      "8080",
+		DBDriver:  "sqlite",
+		Templates: dir,
+	}
+	fsys := generateMem(t, config)
+
+	m := &Manifest{Config: config}
+	m.GeneratorVersion = Version
+	if _, err := Add(fsys, m, "sessions", false); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	got, err := fsys.ReadFile(overriddenMiddleware)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(got, marker) {
+		t.Errorf("%s lost the template override:\n%s", overriddenMiddleware, got)
+	}
+	assertUntouched(t, fsys, overriddenMiddleware)
+}
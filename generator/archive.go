@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// ArchiveFormat selects the container written by an ArchiveFS.
+type ArchiveFormat string
+
+const (
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+	ArchiveZip   ArchiveFormat = "zip"
+)
+
+// ArchiveFormatFor picks the archive format from a file name's extension:
+// ".zip" selects zip, ".tar.gz" and ".tgz" select tar.gz. Any other name is
+// an error, so the content always matches the extension.
+func ArchiveFormatFor(name string) (ArchiveFormat, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return ArchiveZip, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ArchiveTarGz, nil
+	}
+	return "", fmt.Errorf("archive %s: name it .zip, .tar.gz or .tgz", name)
+}
+
+// ArchiveFS is a write-only FS that streams a project into a tar.gz or zip
+// archive. Every entry is stored under prefix (usually the project name).
+// Close must be called to flush the archive.
+type ArchiveFS struct {
+	prefix string
+	dirs   map[string]bool
+	tw     *tar.Writer
+	gz     *gzip.Writer
+	zw     *zip.Writer
+}
+
+// NewArchiveFS returns an ArchiveFS writing an archive of the given format to w.
+func NewArchiveFS(w io.Writer, format ArchiveFormat, prefix string) *ArchiveFS {
+	a := &ArchiveFS{prefix: prefix, dirs: map[string]bool{}}
+	switch format {
+	case ArchiveZip:
+		a.zw = zip.NewWriter(w)
+	default:
+		a.gz = gzip.NewWriter(w)
+		a.tw = tar.NewWriter(a.gz)
+	}
+	return a
+}
+
+func (a *ArchiveFS) entry(name string) string {
+	return path.Join(a.prefix, name)
+}
+
+func (a *ArchiveFS) MkdirAll(dir string) error {
+	dir = path.Clean(dir)
+	if dir == "." || a.dirs[dir] {
+		return nil
+	}
+	if err := a.MkdirAll(path.Dir(dir)); err != nil {
+		return err
+	}
+	a.dirs[dir] = true
+
+	name := a.entry(dir) + "/"
+	if a.zw != nil {
+		_, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Modified: time.Now()})
+		return err
+	}
+	return a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name,
+		Mode:     0755,
+		ModTime:  time.Now(),
+	})
+}
+
+func (a *ArchiveFS) WriteFile(name string, data []byte) error {
+	if err := a.MkdirAll(path.Dir(name)); err != nil {
+		return err
+	}
+	if a.zw != nil {
+		w, err := a.zw.CreateHeader(&zip.FileHeader{Name: a.entry(name), Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	if err := a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     a.entry(name),
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err := a.tw.Write(data)
+	return err
+}
+
+// ReadFile always reports fs.ErrNotExist: an archive starts empty and cannot
+// be read back while it is being written.
+func (a *ArchiveFS) ReadFile(name string) ([]byte, error) {
+	return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir always reports fs.ErrNotExist; see ReadFile.
+func (a *ArchiveFS) ReadDir(dir string) ([]fs.DirEntry, error) {
+	return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrNotExist}
+}
+
+// Close flushes the archive. It does not close the underlying writer.
+func (a *ArchiveFS) Close() error {
+	if a.zw != nil {
+		return a.zw.Close()
+	}
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
+
+// GenerateArchive generates the project described by config and streams it
+// to w as an archive, without touching disk. Entries are stored under a
+// directory named after the project.
+func GenerateArchive(config *Config, w io.Writer, format ArchiveFormat, opts ...Option) error {
+	archive := NewArchiveFS(w, format, config.Name)
+	if err := New(config, append(opts, WithFS(archive))...).Generate(); err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
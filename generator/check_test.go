@@ -0,0 +1,509 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+)
+
+// checkStubs declares the API of every third-party package generated code
+// imports, plus stand-ins for the packages sqlc and templ generate when a
+// project's SQL or .templ files are customized and their generated code is
+// not emitted, so generated projects can be type-checked without a module
+// download or the code generators. Stubs are laid out by import path under
+// testdata/stubs; internal/db is relative to the project module and rendered
+// with the Config, plus the queries of each scaffolded resource.
+var checkStubs = os.DirFS("testdata")
+
+// checkError lists the type errors found in generated Go code.
+type checkError struct {
+	errors []string // "path:line:col: message", relative to the project root
+}
+
+func (e *checkError) Error() string {
+	return fmt.Sprintf("%d type error(s):\n\t%s", len(e.errors), strings.Join(e.errors, "\n\t"))
+}
+
+// typeCheck type-checks the Go packages among files, a generated project
+// keyed by project-relative path, the way the compiler would see them after
+// `make sqlc templ`. resources are the resources scaffolded into the
+// project, whose queries sqlc adds to internal/db. Undefined identifiers,
+// mismatched calls and unused imports or variables are returned as a
+// *checkError.
+func typeCheck(config *Config, files map[string][]byte, resources ...*resourceData) error {
+	c := &checker{
+		config:    config,
+		module:    config.Module,
+		files:     files,
+		resources: resources,
+		fset:      token.NewFileSet(),
+		pkgs:      map[string]*types.Package{},
+		seen:      map[string]bool{},
+	}
+
+	dirs := map[string]bool{}
+	for name := range files {
+		if strings.HasSuffix(name, ".go") {
+			dirs[path.Dir(name)] = true
+		}
+	}
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Strings(sorted)
+
+	for _, dir := range sorted {
+		if err := c.checkDir(dir); err != nil {
+			return err
+		}
+	}
+	if len(c.errs) > 0 {
+		return &checkError{errors: c.errs}
+	}
+	return nil
+}
+
+// checkDatabases are the database flags TestCompileMatrix combines with the
+// feature toggles: every driver, and every SQLite driver.
+func checkDatabases() [][2]string {
+	var dbs [][2]string
+	for _, driver := range DBDrivers {
+		if driver != "sqlite" {
+			dbs = append(dbs, [2]string{driver, ""})
+			continue
+		}
+		for _, sqliteDriver := range SQLiteDrivers {
+			dbs = append(dbs, [2]string{driver, sqliteDriver})
+		}
+	}
+	return dbs
+}
+
+// checkResources are the resources TestCompileMatrix scaffolds, as given
+// to `goth-generate resource`: one with a single field, which sqlc passes
+// as a plain argument, and one with a field of every type.
+var checkResources = [][]string{
+	{"Category", "title:string"},
+	{"Post", "title:string", "body:text", "views:int", "published:bool", "score:float", "published_at:time"},
+}
+
+// TestCompileMatrix type-checks the generated code for every combination
+// of database driver and the auth, users and sessions toggles. Subtests are
+// named like the flags that select the combination, and each has its own
+// subtests: "new" generates the project into a temporary directory,
+// "resource" scaffolds checkResources into it, and "add_<feature>" adds
+// each feature the combination leaves out.
+func TestCompileMatrix(t *testing.T) {
+	for _, db := range checkDatabases() {
+		for bits := 0; bits < 8; bits++ {
+			config := Config{
+				Name:         "app",
+				Module:       "example.com/app",
+				Port:         "8080",
+				DBDriver:     db[0],
+				SQLiteDriver: db[1],
+				WithAuth:     bits&1 != 0,
+				WithUsers:    bits&2 != 0,
+				WithSessions: bits&4 != 0,
+			}
+			name := "-db " + db[0]
+			if db[1] != "" {
+				name += " -sqlite-driver " + db[1]
+			}
+			name += fmt.Sprintf(" -auth=%t -users=%t -sessions=%t", config.WithAuth, config.WithUsers, config.WithSessions)
+
+			t.Run(name, func(t *testing.T) {
+				t.Run("new", func(t *testing.T) {
+					config := config
+					config.OutputDir = t.TempDir()
+					if err := New(&config).Generate(); err != nil {
+						t.Fatalf("Generate: %v", err)
+					}
+					files, err := readProject(config.OutputDir)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := typeCheck(&config, files); err != nil {
+						t.Error(err)
+					}
+				})
+
+				t.Run("resource", func(t *testing.T) {
+					fsys := generateMem(t, config)
+					var resources []*resourceData
+					for _, args := range checkResources {
+						r, err := ParseResource(args[0], args[1:])
+						if err != nil {
+							t.Fatal(err)
+						}
+						g := New(&config, WithFS(fsys))
+						d, err := g.newResourceData(r)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if err := g.GenerateResource(r); err != nil {
+							t.Fatalf("GenerateResource %s: %v", r.Name, err)
+						}
+						resources = append(resources, d)
+					}
+					if err := typeCheck(&config, memFiles(t, fsys), resources...); err != nil {
+						t.Error(err)
+					}
+				})
+
+				for _, feature := range Features {
+					if HasFeature(&config, feature) {
+						continue
+					}
+					t.Run("add "+feature, func(t *testing.T) {
+						fsys := generateMem(t, config)
+						m := &Manifest{Config: config}
+						m.GeneratorVersion = Version
+						results, err := Add(fsys, m, feature, false)
+						if err != nil {
+							t.Fatalf("Add: %v", err)
+						}
+						for _, r := range results {
+							if r.Status == UpgradeConflict || r.Status == UpgradeSkipped {
+								t.Errorf("Add: %s %s %s", r.Status, r.Path, r.Note)
+							}
+						}
+						added := config
+						if err := EnableFeature(&added, feature); err != nil {
+							t.Fatal(err)
+						}
+						if err := typeCheck(&added, memFiles(t, fsys)); err != nil {
+							t.Error(err)
+						}
+					})
+				}
+			})
+		}
+	}
+}
+
+// readProject reads every file of the project in dir, keyed by
+// slash-separated relative path.
+func readProject(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
+
+type checker struct {
+	config    *Config
+	module    string
+	files     map[string][]byte
+	resources []*resourceData
+	fset      *token.FileSet
+	pkgs      map[string]*types.Package
+	seen      map[string]bool // errors already reported
+	errs      []string
+}
+
+// stdImporter type-checks standard library packages from export data. It
+// is shared because loading net/http and its dependencies dominates the
+// cost of a check.
+var stdImporter = sync.OnceValue(func() types.Importer {
+	return importer.ForCompiler(token.NewFileSet(), "gc", nil)
+})
+
+// Import implements types.Importer for generated code.
+func (c *checker) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := c.pkgs[importPath]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle through %s", importPath)
+		}
+		return pkg, nil
+	}
+
+	if importPath == c.module || strings.HasPrefix(importPath, c.module+"/") {
+		dir := strings.TrimPrefix(strings.TrimPrefix(importPath, c.module), "/")
+		if dir == "" {
+			dir = "."
+		}
+		src := c.packageFiles(dir, false)
+		stubs, err := c.generatedStub(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(src) == 0 && len(stubs) == 0 {
+			return nil, fmt.Errorf("package %s has no Go files", importPath)
+		}
+		return c.check(importPath, append(src, stubs...), len(src) == 0)
+	}
+
+	if src, err := stubFiles(importPath); err != nil {
+		return nil, err
+	} else if len(src) > 0 {
+		return c.check(importPath, src, true)
+	}
+
+	if first, _, _ := strings.Cut(importPath, "/"); !strings.Contains(first, ".") {
+		return stdImporter().Import(importPath)
+	}
+	return nil, fmt.Errorf("no checker stub for %s; add one under generator/testdata/stubs", importPath)
+}
+
+// checkDir type-checks the package in dir together with its tests.
+func (c *checker) checkDir(dir string) error {
+	importPath := c.module
+	if dir != "." {
+		importPath += "/" + dir
+	}
+	if _, err := c.Import(importPath); err != nil {
+		return err
+	}
+
+	// In-package tests see the package's unexported names, so check them
+	// with the package; external tests import it.
+	withTests := c.packageFiles(dir, true)
+	var internal, external []namedSource
+	for _, src := range withTests {
+		if strings.HasSuffix(src.name, "_test.go") && strings.HasSuffix(packageName(src.data), "_test") {
+			external = append(external, src)
+		} else {
+			internal = append(internal, src)
+		}
+	}
+	if len(internal) > len(c.packageFiles(dir, false)) {
+		stubs, err := c.generatedStub(dir)
+		if err != nil {
+			return err
+		}
+		if _, err := c.checkUncached(importPath, append(internal, stubs...), false); err != nil {
+			return err
+		}
+	}
+	if len(external) > 0 {
+		if _, err := c.checkUncached(importPath+"_test", external, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type namedSource struct {
+	name string // project-relative path, used in positions
+	data []byte
+	stub bool // stands in for generated code; type errors in it are ignored
+}
+
+// packageFiles returns the Go files directly in dir, optionally with tests.
+func (c *checker) packageFiles(dir string, tests bool) []namedSource {
+	var srcs []namedSource
+	for name, data := range c.files {
+		if path.Dir(name) != dir || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		if strings.HasSuffix(name, "_test.go") && !tests {
+			continue
+		}
+		srcs = append(srcs, namedSource{name: name, data: data})
+	}
+	sort.Slice(srcs, func(i, j int) bool { return srcs[i].name < srcs[j].name })
+	return srcs
+}
+
+func (c *checker) check(importPath string, srcs []namedSource, lenient bool) (*types.Package, error) {
+	c.pkgs[importPath] = nil // marks the package as in progress
+	pkg, err := c.checkUncached(importPath, srcs, lenient)
+	if err != nil {
+		delete(c.pkgs, importPath)
+		return nil, err
+	}
+	c.pkgs[importPath] = pkg
+	return pkg, nil
+}
+
+// checkUncached type-checks srcs as importPath. Errors in lenient (stub)
+// packages are ignored; the stubs only need to declare names.
+func (c *checker) checkUncached(importPath string, srcs []namedSource, lenient bool) (*types.Package, error) {
+	var files []*ast.File
+	stubs := map[string]bool{}
+	for _, src := range srcs {
+		stubs[src.name] = src.stub
+		f, err := parser.ParseFile(c.fset, src.name, src.data, parser.AllErrors|parser.SkipObjectResolution)
+		if err != nil {
+			if lenient {
+				return nil, fmt.Errorf("stub for %s: %w", importPath, err)
+			}
+			c.report(err.Error())
+			continue
+		}
+		files = append(files, f)
+	}
+
+	conf := types.Config{
+		Importer: c,
+		Error: func(err error) {
+			if terr, ok := err.(types.Error); ok && stubs[terr.Fset.Position(terr.Pos).Filename] {
+				return
+			}
+			if !lenient {
+				c.report(err.Error())
+			}
+		},
+	}
+	pkg, _ := conf.Check(importPath, c.fset, files, nil)
+	return pkg, nil
+}
+
+func (c *checker) report(msg string) {
+	if !c.seen[msg] {
+		c.seen[msg] = true
+		c.errs = append(c.errs, msg)
+	}
+}
+
+// generatedStub returns stand-ins for the code generators' output in dir
+// that the project does not include: sqlc's internal/db, with the queries of
+// each resource, or templ's components in web/templates.
+func (c *checker) generatedStub(dir string) ([]namedSource, error) {
+	var srcs []namedSource
+	switch dir {
+	case "internal/db":
+		if len(c.packageFiles(dir, false)) == 0 {
+			src, err := renderStub("db.go", c.config)
+			if err != nil {
+				return nil, err
+			}
+			srcs = append(srcs, src)
+		}
+		for _, d := range c.resources {
+			name := "internal/db/" + d.Table + ".sql.go"
+			if _, ok := c.files[name]; ok {
+				continue
+			}
+			src, err := renderStub("resource.go", d)
+			if err != nil {
+				return nil, err
+			}
+			src.name = name
+			srcs = append(srcs, src)
+		}
+	case "web/templates":
+		for name, data := range c.files {
+			if path.Dir(name) != dir || !strings.HasSuffix(name, ".templ") {
+				continue
+			}
+			if _, ok := c.files[strings.TrimSuffix(name, ".templ")+"_templ.go"]; ok {
+				continue
+			}
+			srcs = append(srcs, namedSource{name: name + ".go", data: templStub(data), stub: true})
+		}
+		sort.Slice(srcs, func(i, j int) bool { return srcs[i].name < srcs[j].name })
+	}
+	return srcs, nil
+}
+
+// renderStub renders the internal/db stub template name with data.
+func renderStub(name string, data any) (namedSource, error) {
+	tmpl, err := fs.ReadFile(checkStubs, "stubs/internal/db/"+name+".tmpl")
+	if err != nil {
+		return namedSource{}, err
+	}
+	t, err := template.New(name).Parse(string(tmpl))
+	if err != nil {
+		return namedSource{}, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return namedSource{}, err
+	}
+	return namedSource{name: "internal/db/" + name, data: buf.Bytes(), stub: true}, nil
+}
+
+var templDecl = regexp.MustCompile(`^templ\s+(\w+)\s*\((.*)\)\s*\{\s*$`)
+
+// templStub declares the components of a .templ file as the functions templ
+// would generate for them. The Go header (package and imports) and
+// top-level Go functions are kept as they are.
+func templStub(src []byte) []byte {
+	var out bytes.Buffer
+	inGo, header := false, true
+	for _, line := range strings.Split(string(src), "\n") {
+		if m := templDecl.FindStringSubmatch(line); m != nil {
+			if header {
+				out.WriteString("import \"github.com/a-h/templ\"\n\n")
+				header = false
+			}
+			fmt.Fprintf(&out, "func %s(%s) templ.Component\n\n", m[1], m[2])
+			continue
+		}
+		switch {
+		case header && !strings.HasPrefix(line, "func "):
+			out.WriteString(line + "\n")
+		case strings.HasPrefix(line, "func "):
+			header, inGo = false, true
+			out.WriteString(line + "\n")
+		case inGo:
+			out.WriteString(line + "\n")
+			if line == "}" {
+				inGo = false
+			}
+		}
+	}
+	if header {
+		out.WriteString("import \"github.com/a-h/templ\"\n")
+	}
+	return out.Bytes()
+}
+
+// stubFiles returns the checker stub for a third-party import path, if any.
+func stubFiles(importPath string) ([]namedSource, error) {
+	entries, err := fs.ReadDir(checkStubs, "stubs/"+importPath)
+	if err != nil {
+		return nil, nil
+	}
+	var srcs []namedSource
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go.stub") {
+			continue
+		}
+		data, err := fs.ReadFile(checkStubs, "stubs/"+importPath+"/"+e.Name())
+		if err != nil {
+			return nil, err
+		}
+		srcs = append(srcs, namedSource{name: importPath + "/" + strings.TrimSuffix(e.Name(), ".stub"), data: data})
+	}
+	return srcs, nil
+}
+
+// packageName returns the name in the package clause of a Go source file.
+func packageName(src []byte) string {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.PackageClauseOnly)
+	if err != nil {
+		return ""
+	}
+	return f.Name.Name
+}
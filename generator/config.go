@@ -2,13 +2,48 @@ package generator
 
 // Config holds the configuration for project generation
 type Config struct {
-	Name         string
-	Module       string
-	OutputDir    string
-	DBDriver     string
-	Port         string
-	WithAuth     bool
-	WithUsers    bool
-	WithSessions bool
-	GoVersion    string // e.g. "1.24" - populated from `go version` at generation time
+	Name             string `yaml:"name" json:"name"`
+	Module           string `yaml:"module" json:"module"`
+	OutputDir        string `yaml:"-" json:"-"`
+	DBDriver         string `yaml:"db_driver" json:"db_driver"`
+	SQLiteDriver     string `yaml:"sqlite_driver,omitempty" json:"sqlite_driver,omitempty"` // with DBDriver "sqlite": "mattn" (the default) or "modernc"
+	Port             string `yaml:"port" json:"port"`
+	WithAuth         bool   `yaml:"auth" json:"auth"`
+	WithUsers        bool   `yaml:"users" json:"users"`
+	WithSessions     bool   `yaml:"sessions" json:"sessions"`
+	GoVersion        string `yaml:"go_version" json:"go_version"`               // e.g. "1.24" - populated from `go version` at generation time
+	GeneratorVersion string `yaml:"generator_version" json:"generator_version"` // set by Generate; recorded in the manifest
+
+	Templates string         `yaml:"templates,omitempty" json:"templates,omitempty"` // template override directory, by absolute path; see TemplateLayers
+	Packs     []string       `yaml:"packs,omitempty" json:"packs,omitempty"`         // template packs, by path; see LoadPack
+	Extra     map[string]any `yaml:"extra,omitempty" json:"extra,omitempty"`         // settings for pack templates, available as .Extra
+
+	Hooks []HookCommand `yaml:"hooks,omitempty" json:"hooks,omitempty"` // shell commands run around generation steps
+}
+
+// Placeholder returns the SQL placeholder for the nth query parameter in the
+// dialect of the database driver, for the query templates.
+func (c *Config) Placeholder(n int) string {
+	return placeholder(c.DBDriver, n)
+}
+
+// PureGoSQLite reports whether the project uses SQLite through the CGO-free
+// modernc.org/sqlite rather than mattn/go-sqlite3.
+func (c *Config) PureGoSQLite() bool {
+	return c.DBDriver == "sqlite" && c.SQLiteDriver == "modernc"
+}
+
+// SQLiteDriverName is the database/sql driver name of the SQLite driver,
+// which is also its golang-migrate URL scheme.
+func (c *Config) SQLiteDriverName() string {
+	if c.PureGoSQLite() {
+		return "sqlite"
+	}
+	return "sqlite3"
+}
+
+// NeedsCGO reports whether the database driver needs CGO, which rules out
+// static binaries and plain cross-compiling.
+func (c *Config) NeedsCGO() bool {
+	return c.DBDriver == "sqlite" && !c.PureGoSQLite()
 }
@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// ConflictStrategy decides what happens when a generated file already
+// exists with different content.
+type ConflictStrategy string
+
+const (
+	// ConflictRefuse aborts generation before anything is written. It is the default.
+	ConflictRefuse ConflictStrategy = "refuse"
+	// ConflictSkip keeps the existing file.
+	ConflictSkip ConflictStrategy = "skip"
+	// ConflictForce overwrites the existing file.
+	ConflictForce ConflictStrategy = "force"
+	// ConflictBackup saves the existing file as <name>.orig, then overwrites it.
+	ConflictBackup ConflictStrategy = "backup"
+	// ConflictDiff keeps the existing file and records a diff against the
+	// generated content in PlannedFile.Diff.
+	ConflictDiff ConflictStrategy = "diff"
+)
+
+// ConflictStrategies lists every strategy accepted by ParseConflictStrategy.
+var ConflictStrategies = []ConflictStrategy{ConflictRefuse, ConflictSkip, ConflictForce, ConflictBackup, ConflictDiff}
+
+// ParseConflictStrategy validates a strategy name such as "backup".
+func ParseConflictStrategy(s string) (ConflictStrategy, error) {
+	for _, cs := range ConflictStrategies {
+		if string(cs) == s {
+			return cs, nil
+		}
+	}
+	return "", fmt.Errorf("unknown conflict strategy %q", s)
+}
+
+// FileAction records what Generate did with a generated file.
+type FileAction string
+
+const (
+	ActionCreate    FileAction = "create"    // the file did not exist
+	ActionUnchanged FileAction = "unchanged" // the file already had the generated content
+	ActionOverwrite FileAction = "overwrite" // ConflictForce
+	ActionBackup    FileAction = "backup"    // ConflictBackup
+	ActionSkip      FileAction = "skip"      // ConflictSkip
+	ActionConflict  FileAction = "conflict"  // ConflictDiff, or any conflict found by a dry run
+)
+
+// ConflictError is returned by Generate under ConflictRefuse when existing
+// files differ from the generated output. Nothing has been written.
+type ConflictError struct {
+	Paths []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("refusing to overwrite %d modified file(s): %s", len(e.Paths), strings.Join(e.Paths, ", "))
+}
+
+// WithConflictStrategy sets how Generate treats existing files that differ
+// from the generated output.
+func WithConflictStrategy(s ConflictStrategy) Option {
+	return func(g *Generator) {
+		g.strategy = s
+	}
+}
+
+// apply compares every rendered file against the target FS, decides its
+// action, plans the updated ledger and, unless this is a dry run, writes
+// the result.
+func (g *Generator) apply() error {
+	ledger, err := ReadLedger(g.fs)
+	if err != nil {
+		return err
+	}
+
+	var conflicts []string
+	existing := map[string][]byte{}
+	for i := range g.files {
+		f := &g.files[i]
+		old, err := g.fs.ReadFile(f.Path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			f.Action = ActionCreate
+			continue
+		case err != nil:
+			return err
+		case bytes.Equal(old, f.Content):
+			f.Action = ActionUnchanged
+			continue
+		case ledger.Untouched(f.Path, old):
+			// Still exactly what an earlier run wrote, so nothing of the
+			// user's is lost by replacing it.
+			f.Action = ActionOverwrite
+			f.Diff = UnifiedDiff(f.Path, old, f.Content)
+			continue
+		}
+
+		conflicts = append(conflicts, f.Path)
+		existing[f.Path] = old
+		f.Diff = UnifiedDiff(f.Path, old, f.Content)
+		switch g.strategy {
+		case ConflictSkip:
+			f.Action = ActionSkip
+		case ConflictForce:
+			f.Action = ActionOverwrite
+		case ConflictBackup:
+			f.Action = ActionBackup
+		default:
+			f.Action = ActionConflict
+		}
+	}
+
+	if err := g.planLedger(ledger); err != nil {
+		return err
+	}
+
+	if g.dryRun {
+		return nil
+	}
+	if len(conflicts) > 0 && g.strategy == ConflictRefuse {
+		return &ConflictError{Paths: conflicts}
+	}
+
+	for _, dir := range g.dirs {
+		if err := g.fs.MkdirAll(dir); err != nil {
+			return err
+		}
+	}
+	for _, f := range g.files {
+		switch f.Action {
+		case ActionBackup:
+			if err := g.fs.WriteFile(f.Path+".orig", existing[f.Path]); err != nil {
+				return fmt.Errorf("failed to back up %s: %w", f.Path, err)
+			}
+			fallthrough
+		case ActionCreate, ActionOverwrite:
+			if err := g.fs.WriteFile(f.Path, f.Content); err != nil {
+				return fmt.Errorf("failed to write %s: %w", f.Path, err)
+			}
+		}
+	}
+	return nil
+}
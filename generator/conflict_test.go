@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+)
+
+const editedReadme = "# My app\n\nRewritten by hand.\n"
+
+// conflictProject generates a project into a MemFS and edits its README, so
+// regenerating it conflicts on README.md.
+func conflictProject(t *testing.T) (Config, *MemFS) {
+	t.Helper()
+	config := Config{Name: "app", Module: "example.com/app", Port: "8080", DBDriver: "sqlite"}
+	fsys := generateMem(t, config)
+	if err := fsys.WriteFile("README.md", []byte(editedReadme)); err != nil {
+		t.Fatal(err)
+	}
+	return config, fsys
+}
+
+// regenerate runs Generate again over fsys with strategy and returns the
+// planned README.md.
+func regenerate(t *testing.T, config Config, fsys *MemFS, strategy ConflictStrategy) PlannedFile {
+	t.Helper()
+	g := New(&config, WithFS(fsys), WithConflictStrategy(strategy))
+	if err := g.Generate(); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	for _, f := range g.Files() {
+		if f.Path == "README.md" {
+			return f
+		}
+	}
+	t.Fatal("README.md was not generated")
+	return PlannedFile{}
+}
+
+func readString(t *testing.T, fsys FS, name string) string {
+	t.Helper()
+	data, err := fsys.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestConflictRefuse(t *testing.T) {
+	config, fsys := conflictProject(t)
+	before := memFiles(t, fsys)
+
+	err := New(&config, WithFS(fsys)).Generate()
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) || len(conflictErr.Paths) != 1 || conflictErr.Paths[0] != "README.md" {
+		t.Fatalf("Generate: err = %v, want a ConflictError for README.md", err)
+	}
+	if after := memFiles(t, fsys); !maps.EqualFunc(before, after, bytes.Equal) {
+		t.Error("a refused generation wrote files")
+	}
+}
+
+func TestConflictBackup(t *testing.T) {
+	config, fsys := conflictProject(t)
+	f := regenerate(t, config, fsys, ConflictBackup)
+	if f.Action != ActionBackup {
+		t.Errorf("action = %s, want %s", f.Action, ActionBackup)
+	}
+	if got := readString(t, fsys, "README.md.orig"); got != editedReadme {
+		t.Errorf("README.md.orig = %q, want the edited README", got)
+	}
+	if got := readString(t, fsys, "README.md"); got != string(f.Content) {
+		t.Errorf("README.md was not replaced with the generated content")
+	}
+	assertUntouched(t, fsys, "README.md")
+}
+
+func TestConflictSkip(t *testing.T) {
+	config, fsys := conflictProject(t)
+	f := regenerate(t, config, fsys, ConflictSkip)
+	if f.Action != ActionSkip {
+		t.Errorf("action = %s, want %s", f.Action, ActionSkip)
+	}
+	if got := readString(t, fsys, "README.md"); got != editedReadme {
+		t.Errorf("README.md = %q, want the edited README kept", got)
+	}
+}
+
+func TestConflictDiff(t *testing.T) {
+	config, fsys := conflictProject(t)
+	f := regenerate(t, config, fsys, ConflictDiff)
+	if f.Action != ActionConflict {
+		t.Errorf("action = %s, want %s", f.Action, ActionConflict)
+	}
+	if !strings.Contains(f.Diff, "-Rewritten by hand.") {
+		t.Errorf("diff does not show the edit:\n%s", f.Diff)
+	}
+	if got := readString(t, fsys, "README.md"); got != editedReadme {
+		t.Errorf("README.md = %q, want the edited README kept", got)
+	}
+}
+
+func TestConflictForce(t *testing.T) {
+	config, fsys := conflictProject(t)
+	f := regenerate(t, config, fsys, ConflictForce)
+	if f.Action != ActionOverwrite {
+		t.Errorf("action = %s, want %s", f.Action, ActionOverwrite)
+	}
+	if got := readString(t, fsys, "README.md"); got != string(f.Content) {
+		t.Errorf("README.md was not replaced with the generated content")
+	}
+}
+
+func TestConflictUntouchedOverwritten(t *testing.T) {
+	config := Config{Name: "app", Module: "example.com/app", Port: "8080", DBDriver: "sqlite"}
+	fsys := generateMem(t, config)
+	before := readString(t, fsys, ".env.example")
+
+	// A new port changes files the ledger shows as untouched; the default
+	// strategy replaces them instead of refusing.
+	config.Port = "9090"
+	g := New(&config, WithFS(fsys))
+	if err := g.Generate(); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	after := readString(t, fsys, ".env.example")
+	if after == before || !strings.Contains(after, "9090") {
+		t.Errorf(".env.example was not regenerated:\n%s", after)
+	}
+	for _, f := range g.Files() {
+		if f.Path == ".env.example" && f.Action != ActionOverwrite {
+			t.Errorf("action = %s, want %s", f.Action, ActionOverwrite)
+		}
+	}
+	assertUntouched(t, fsys, ".env.example")
+}
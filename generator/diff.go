@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffOp is one line of an edit script: '=' keeps a line present in both
+// inputs, '-' deletes a line of the old input and '+' inserts a new line.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// splitLines splits s into lines, keeping each line's trailing newline.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script turning a into b, computed from
+// the longest common subsequence. Generated files are small enough for the
+// quadratic table.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{'=', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// UnifiedDiff returns a unified diff (3 lines of context) from oldContent to
+// newContent, labelled with name. It returns "" when the contents are equal.
+func UnifiedDiff(name string, oldContent, newContent []byte) string {
+	ops := diffLines(splitLines(string(oldContent)), splitLines(string(newContent)))
+
+	const context = 3
+	var b strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change.
+		for start < len(ops) && ops[start].kind == '=' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		// Extend the hunk until a run of unchanged lines long enough to
+		// separate it from the next change.
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != '=' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == '=' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				break
+			}
+			end = run
+		}
+		lo := max(start-context, 0)
+		hi := min(end+context, len(ops))
+
+		// Line numbers of the hunk in the old and new input.
+		oldLine, newLine := 1, 1
+		for _, op := range ops[:lo] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		var oldCount, newCount int
+		for _, op := range ops[lo:hi] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s (existing)\n+++ %s (generated)\n", name, name)
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		for _, op := range ops[lo:hi] {
+			prefix := " "
+			if op.kind != '=' {
+				prefix = string(op.kind)
+			}
+			b.WriteString(prefix + op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = hi
+	}
+	return b.String()
+}
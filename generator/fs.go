@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FS is the filesystem a Generator writes a project into. Names are
+// slash-separated and relative to the project root.
+type FS interface {
+	MkdirAll(dir string) error
+	WriteFile(name string, data []byte) error
+	// ReadFile and ReadDir return an error satisfying
+	// errors.Is(err, fs.ErrNotExist) for missing paths.
+	ReadFile(name string) ([]byte, error)
+	ReadDir(dir string) ([]fs.DirEntry, error)
+}
+
+// DirFS returns an FS rooted at dir on the real disk.
+func DirFS(dir string) FS {
+	return &diskFS{root: dir}
+}
+
+type diskFS struct {
+	root string
+}
+
+func (d *diskFS) path(name string) string {
+	return filepath.Join(d.root, filepath.FromSlash(name))
+}
+
+func (d *diskFS) MkdirAll(dir string) error {
+	return os.MkdirAll(d.path(dir), 0755)
+}
+
+func (d *diskFS) WriteFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(d.path(name)), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(d.path(name), data, 0644)
+}
+
+func (d *diskFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(d.path(name))
+}
+
+func (d *diskFS) ReadDir(dir string) ([]fs.DirEntry, error) {
+	return os.ReadDir(d.path(dir))
+}
+
+// MemFS is an in-memory FS, used for dry runs, tests and embedding the
+// generator as a library. The zero value is not usable; call NewMemFS.
+type MemFS struct {
+	files map[string][]byte
+	order []string
+	dirs  map[string]bool
+}
+
+// NewMemFS returns an empty MemFS.
+func NewMemFS() *MemFS {
+	return &MemFS{
+		files: map[string][]byte{},
+		dirs:  map[string]bool{".": true},
+	}
+}
+
+func (m *MemFS) MkdirAll(dir string) error {
+	for dir = path.Clean(dir); !m.dirs[dir]; dir = path.Dir(dir) {
+		m.dirs[dir] = true
+	}
+	return nil
+}
+
+func (m *MemFS) WriteFile(name string, data []byte) error {
+	name = path.Clean(name)
+	if err := m.MkdirAll(path.Dir(name)); err != nil {
+		return err
+	}
+	if _, ok := m.files[name]; !ok {
+		m.order = append(m.order, name)
+	}
+	m.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	data, ok := m.files[path.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *MemFS) ReadDir(dir string) ([]fs.DirEntry, error) {
+	dir = path.Clean(dir)
+	if !m.dirs[dir] {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrNotExist}
+	}
+	seen := map[string]fs.DirEntry{}
+	add := func(p string, isDir bool) {
+		parent, base := path.Split(p)
+		if path.Clean(parent) == dir {
+			seen[base] = memDirEntry{name: base, dir: isDir, size: int64(len(m.files[p]))}
+		}
+	}
+	for d := range m.dirs {
+		if d != "." {
+			add(d, true)
+		}
+	}
+	for f := range m.files {
+		add(f, false)
+	}
+	entries := make([]fs.DirEntry, 0, len(seen))
+	for _, e := range seen {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Paths returns the paths of all files in the order they were first written.
+func (m *MemFS) Paths() []string {
+	return append([]string(nil), m.order...)
+}
+
+// Dirs returns every directory in the filesystem, sorted.
+func (m *MemFS) Dirs() []string {
+	var dirs []string
+	for d := range m.dirs {
+		if d != "." {
+			dirs = append(dirs, d)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// WriteTo copies every file and directory into dst, e.g. a DirFS.
+func (m *MemFS) WriteTo(dst FS) error {
+	for _, d := range m.Dirs() {
+		if err := dst.MkdirAll(d); err != nil {
+			return err
+		}
+	}
+	for _, name := range m.order {
+		if err := dst.WriteFile(name, m.files[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type memDirEntry struct {
+	name string
+	dir  bool
+	size int64
+}
+
+func (e memDirEntry) Name() string               { return e.name }
+func (e memDirEntry) IsDir() bool                { return e.dir }
+func (e memDirEntry) Type() fs.FileMode          { return e.Mode().Type() }
+func (e memDirEntry) Info() (fs.FileInfo, error) { return e, nil }
+func (e memDirEntry) Size() int64                { return e.size }
+func (e memDirEntry) ModTime() time.Time         { return time.Time{} }
+func (e memDirEntry) Sys() any                   { return nil }
+
+func (e memDirEntry) Mode() fs.FileMode {
+	if e.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
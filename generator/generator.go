@@ -2,31 +2,98 @@ package generator
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"path"
 	"strings"
-	"text/template"
 )
 
+// defaultGoVersion is used when Config.GoVersion is not set.
+const defaultGoVersion = "1.23"
+
 type Generator struct {
-	config *Config
+	config   *Config
+	fs       FS
+	dryRun   bool
+	strategy ConflictStrategy
+	step     string
+
+	reproducing bool // an existing project as recorded: no validation, no hooks
+
+	templates []fs.FS // override layers, highest priority first
+	packs     []*Pack
+	hooks     []registeredHook
+
+	dirs     []string
+	files    []PlannedFile
+	skipped  []SkippedStep
+	warnings []string
+}
+
+// Option configures a Generator.
+type Option func(*Generator)
+
+// WithFS makes the Generator write into fsys instead of Config.OutputDir on disk.
+func WithFS(fsys FS) Option {
+	return func(g *Generator) {
+		g.fs = fsys
+	}
 }
 
-func New(config *Config) *Generator {
-	return &Generator{
-		config: config,
+// WithDryRun makes Generate run every step and compare the result with the
+// target FS without writing anything. The directories and files it would
+// have created, and what it would have done with each, are available from
+// Dirs and Files afterwards.
+func WithDryRun() Option {
+	return func(g *Generator) {
+		g.dryRun = true
 	}
 }
 
+// PlannedFile is a file rendered by Generate. Path is relative to the
+// project root.
+type PlannedFile struct {
+	Path    string
+	Step    string // generation step that produced the file
+	Content []byte
+	Action  FileAction
+	Diff    string // unified diff against the existing file, when they differ
+}
+
+func New(config *Config, opts ...Option) *Generator {
+	g := &Generator{
+		config:   config,
+		strategy: ConflictRefuse,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.fs == nil {
+		g.fs = DirFS(config.OutputDir)
+	}
+	return g
+}
+
+// generateStep is one named step of Generate.
+type generateStep struct {
+	name string
+	fn   func() error
+}
+
+// Generate renders the project and writes it to the target FS. An invalid
+// Config is rejected with a *ValidationError before anything is rendered.
+// Hooks run around each step and around writing the files; see WithHook.
 func (g *Generator) Generate() error {
-	if err := os.MkdirAll(g.config.OutputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create project directory: %w", err)
+	if !g.reproducing {
+		if err := g.config.Validate(); err != nil {
+			return err
+		}
+	}
+	g.config.GeneratorVersion = Version
+	if g.config.GoVersion == "" {
+		g.config.GoVersion = defaultGoVersion
 	}
 
-	steps := []struct {
-		name string
-		fn   func() error
-	}{
+	steps := []generateStep{
 		{"project structure", g.generateStructure},
 		{"main.go", g.generateMain},
 		{"database", g.generateDatabase},
@@ -43,40 +110,105 @@ func (g *Generator) Generate() error {
 		{"makefile", g.generateMakefile},
 		{"readme", g.generateReadme},
 		{"go mod", g.generateGoMod},
+		{"manifest", g.generateManifest},
 	}
 
+	steps, err := g.applyPacks(steps)
+	if err != nil {
+		return err
+	}
+	if err := g.checkHooks(steps); err != nil {
+		return err
+	}
+
+	// Render every step before touching the target, so a refused conflict
+	// leaves the project exactly as it was.
 	for _, step := range steps {
+		g.step = step.name
+		if err := g.runHooks(HookBefore, step.name); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", step.name, err)
+		}
 		if err := step.fn(); err != nil {
 			return fmt.Errorf("failed to generate %s: %w", step.name, err)
 		}
+		if err := g.runHooks(HookAfter, step.name); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", step.name, err)
+		}
 	}
 
+	g.step = StepWrite
+	if err := g.runHooks(HookBefore, StepWrite); err != nil {
+		return fmt.Errorf("failed to write project: %w", err)
+	}
+	if err := g.apply(); err != nil {
+		return err
+	}
+	if err := g.runHooks(HookAfter, StepWrite); err != nil {
+		return fmt.Errorf("failed to write project: %w", err)
+	}
 	return nil
 }
 
-func (g *Generator) projectPath(paths ...string) string {
-	allPaths := append([]string{g.config.OutputDir}, paths...)
-	return filepath.Join(allPaths...)
+// SkippedStep is a generation step that produced nothing for the Config.
+type SkippedStep struct {
+	Step   string `json:"step"`
+	Reason string `json:"reason"`
 }
 
-func (g *Generator) writeFile(path string, content string) error {
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-	return os.WriteFile(path, []byte(content), 0644)
+// Skipped returns the steps Generate skipped, such as the session service
+// when sessions are disabled.
+func (g *Generator) Skipped() []SkippedStep {
+	return g.skipped
 }
 
-func (g *Generator) writeTemplate(path string, tmpl string, data interface{}) error {
-	t, err := template.New("").Parse(tmpl)
-	if err != nil {
-		return err
-	}
+// Warnings returns what Generate could not do for the Config but the user
+// can, such as regenerating code for customized templates.
+func (g *Generator) Warnings() []string {
+	return g.warnings
+}
 
-	var buf strings.Builder
-	if err := t.Execute(&buf, data); err != nil {
-		return err
-	}
+// skip records the current step as skipped and returns nil, for steps to
+// return early with.
+func (g *Generator) skip(reason string) error {
+	g.skipped = append(g.skipped, SkippedStep{Step: g.step, Reason: reason})
+	return nil
+}
+
+func (g *Generator) warn(format string, args ...any) {
+	g.warnings = append(g.warnings, fmt.Sprintf(format, args...))
+}
+
+// Dirs returns the directories created by Generate, relative to the project root.
+func (g *Generator) Dirs() []string {
+	return g.dirs
+}
+
+// Files returns the files rendered by Generate in the order they were rendered.
+func (g *Generator) Files() []PlannedFile {
+	return g.files
+}
+
+// projectPath joins paths into a slash-separated path relative to the project root.
+func (g *Generator) projectPath(paths ...string) string {
+	return path.Join(paths...)
+}
 
-	return g.writeFile(path, buf.String())
+// mkdir records a directory to create when the rendered files are applied.
+func (g *Generator) mkdir(dir string) error {
+	g.dirs = append(g.dirs, dir)
+	return nil
+}
+
+// writeFile records a file to write when the rendered files are applied.
+// Go sources are formatted first; see formatGo.
+func (g *Generator) writeFile(path string, content string) error {
+	if strings.HasSuffix(path, ".go") {
+		out, err := formatGo(path, []byte(content))
+		if err != nil {
+			return err
+		}
+		content = string(out)
+	}
+	g.files = append(g.files, PlannedFile{Path: path, Step: g.step, Content: []byte(content)})
+	return nil
 }
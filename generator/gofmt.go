@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formatGo gofmt's a rendered Go file and drops the imports it does not use,
+// so `{{if}}` blocks in templates can't leave blank lines, misaligned imports
+// or unused packages behind. Syntax errors name the file and line.
+func formatGo(name string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid Go: %w", err)
+	}
+	if cuts := unusedImports(fset, file, src); len(cuts) > 0 {
+		src = cutRanges(src, cuts)
+	}
+	out, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid Go: %s:%w", name, err)
+	}
+	return out, nil
+}
+
+// unusedImports returns the byte ranges of the import specs in file whose
+// package is never referenced, whole lines where the spec has one to itself.
+// An import declaration left without specs is removed entirely.
+func unusedImports(fset *token.FileSet, file *ast.File, src []byte) [][2]int {
+	used := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			// Identifiers resolved by the parser are local declarations
+			// shadowing a package name.
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+
+	var cuts [][2]int
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var unused []ast.Spec
+		for _, spec := range gen.Specs {
+			if name := importName(spec.(*ast.ImportSpec)); name != "" && !used[name] {
+				unused = append(unused, spec)
+			}
+		}
+		if len(unused) == len(gen.Specs) && len(unused) > 0 {
+			cuts = append(cuts, lineRange(fset, src, gen.Pos(), gen.End()))
+			continue
+		}
+		for _, spec := range unused {
+			start, end := spec.Pos(), spec.End()
+			if c := spec.(*ast.ImportSpec).Comment; c != nil {
+				end = c.End()
+			}
+			cuts = append(cuts, lineRange(fset, src, start, end))
+		}
+	}
+	return cuts
+}
+
+// importName returns the name an import is referred to by, or "" for blank
+// and dot imports, which can't be unused. Without an explicit name it is
+// guessed from the path the way goimports does: the last element, minus a
+// major version suffix, a "go-" prefix and anything after the first
+// character that can't be part of an identifier.
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		if spec.Name.Name == "_" || spec.Name.Name == "." {
+			return ""
+		}
+		return spec.Name.Name
+	}
+	path, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return ""
+	}
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// isMajorVersion reports whether elem is a module major version suffix (v2, v3...).
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(elem[1:])
+	return err == nil
+}
+
+// lineRange returns the byte range from pos to end, widened to whole lines
+// when nothing else shares them.
+func lineRange(fset *token.FileSet, src []byte, pos, end token.Pos) [2]int {
+	start, stop := fset.Position(pos).Offset, fset.Position(end).Offset
+	lineStart := strings.LastIndexByte(string(src[:start]), '\n') + 1
+	if strings.TrimSpace(string(src[lineStart:start])) == "" {
+		start = lineStart
+	}
+	if i := strings.IndexByte(string(src[stop:]), '\n'); i >= 0 && strings.TrimSpace(string(src[stop:stop+i])) == "" {
+		stop += i + 1
+	}
+	return [2]int{start, stop}
+}
+
+// cutRanges removes the given non-overlapping byte ranges from src.
+func cutRanges(src []byte, cuts [][2]int) []byte {
+	sort.Slice(cuts, func(i, j int) bool { return cuts[i][0] < cuts[j][0] })
+	var out []byte
+	prev := 0
+	for _, c := range cuts {
+		out = append(out, src[prev:c[0]]...)
+		prev = c[1]
+	}
+	return append(out, src[prev:]...)
+}
@@ -1,15 +1,107 @@
 package generator
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"os/exec"
+	"io/fs"
+	"sort"
+	"strconv"
 	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
+// MinGoVersion is the oldest Go release the pinned dependencies of generated
+// projects support.
+const MinGoVersion = "1.23"
+
+// dependency is a module generated projects require, pinned to the version
+// the generated code is tested with.
+type dependency struct {
+	path    string
+	version string
+	direct  func(*Config) bool // generated code imports it; nil: never
+	needed  func(*Config) bool // it is in the module graph; nil: always
+}
+
+// dependencies lists every module in the build of a generated project. The
+// indirect ones are what `go mod tidy` adds for the direct ones, so the
+// project builds without tidying first. Keep go.sum in the templates in step
+// when changing versions.
+var dependencies = []dependency{
+	{path: "github.com/a-h/templ", version: "v0.3.977", direct: always},
+	{path: "github.com/go-sql-driver/mysql", version: "v1.9.3", direct: usesMySQL, needed: usesMySQL},
+	{path: "github.com/golang-migrate/migrate/v4", version: "v4.19.0", direct: always},
+	{path: "github.com/google/uuid", version: "v1.6.0", direct: withSessions, needed: needsUUID},
+	{path: "github.com/jackc/pgx/v5", version: "v5.7.6", direct: usesPostgres, needed: usesPostgres},
+	{path: "github.com/joho/godotenv", version: "v1.5.1", direct: always},
+	{path: "github.com/julienschmidt/httprouter", version: "v1.3.0", direct: always},
+	{path: "github.com/justinas/nosurf", version: "v1.2.0", direct: always},
+	{path: "github.com/mattn/go-sqlite3", version: "v1.14.32", direct: usesMattn, needed: usesMattn},
+	{path: "golang.org/x/crypto", version: "v0.41.0", direct: withUserService, needed: needsCrypto},
+	{path: "golang.org/x/term", version: "v0.34.0", direct: always},
+	{path: "modernc.org/sqlite", version: "v1.39.0", direct: usesModernc, needed: usesModernc},
+
+	{path: "filippo.io/edwards25519", version: "v1.1.0", needed: usesMySQL},
+	{path: "github.com/dustin/go-humanize", version: "v1.0.1", needed: usesModernc},
+	{path: "github.com/hashicorp/errwrap", version: "v1.1.0"},
+	{path: "github.com/hashicorp/go-multierror", version: "v1.1.1"},
+	{path: "github.com/jackc/pgpassfile", version: "v1.0.0", needed: usesPostgres},
+	{path: "github.com/jackc/pgservicefile", version: "v0.0.0-20240606120523-5a60cdf6a761", needed: usesPostgres},
+	{path: "github.com/jackc/puddle/v2", version: "v2.2.2", needed: usesPostgres},
+	{path: "github.com/lib/pq", version: "v1.10.9", needed: usesPostgres},
+	{path: "github.com/mattn/go-isatty", version: "v0.0.20", needed: usesModernc},
+	{path: "github.com/ncruces/go-strftime", version: "v0.1.9", needed: usesModernc},
+	{path: "github.com/remyoudompheng/bigfft", version: "v0.0.0-20230129092748-24d4a6f8daec", needed: usesModernc},
+	{path: "golang.org/x/exp", version: "v0.0.0-20250620022241-b7579e27df2b", needed: usesModernc},
+	{path: "golang.org/x/sync", version: "v0.16.0", needed: usesPostgres},
+	{path: "golang.org/x/sys", version: "v0.35.0"},
+	{path: "golang.org/x/text", version: "v0.28.0", needed: usesPostgres},
+	{path: "modernc.org/libc", version: "v1.66.3", needed: usesModernc},
+	{path: "modernc.org/mathutil", version: "v1.7.1", needed: usesModernc},
+	{path: "modernc.org/memory", version: "v1.11.0", needed: usesModernc},
+}
+
+func always(*Config) bool            { return true }
+func withSessions(c *Config) bool    { return c.WithSessions }
+func withUserService(c *Config) bool { return c.WithUsers || c.WithAuth }
+func usesPostgres(c *Config) bool    { return c.DBDriver == "postgres" }
+func usesMattn(c *Config) bool       { return c.DBDriver == "sqlite" && !c.PureGoSQLite() }
+func usesModernc(c *Config) bool     { return c.PureGoSQLite() }
+func usesMySQL(c *Config) bool       { return c.DBDriver == "mysql" }
+
+// pgx needs x/crypto even without bcrypt. modernc.org/sqlite needs
+// google/uuid even without sessions.
+func needsCrypto(c *Config) bool { return usesPostgres(c) || withUserService(c) }
+func needsUUID(c *Config) bool   { return withSessions(c) || usesModernc(c) }
+
+// TemplVersion is the pinned templ version, for running the templ command
+// that matches the project's runtime.
+func (c *Config) TemplVersion() string {
+	return dependencyVersion("github.com/a-h/templ")
+}
+
+func dependencyVersion(path string) string {
+	for _, dep := range dependencies {
+		if dep.path == path {
+			return dep.version
+		}
+	}
+	return "latest"
+}
+
 func (g *Generator) generateGoMod() error {
-	projectDir := g.projectPath("")
+	// Re-generating into an existing project keeps its go.mod and dependencies
+	if _, err := g.fs.ReadFile(g.projectPath("go.mod")); err == nil {
+		return g.skip("go.mod already exists")
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
 
-	// Run go mod init with module path (or app name if not provided)
+	// Module path (or app name if not provided)
 	module := g.config.Module
 	if module == "" {
 		module = g.config.Name
@@ -22,12 +114,169 @@ func (g *Generator) generateGoMod() error {
 		}
 	}
 
-	initCmd := exec.Command("go", "mod", "init", module)
-	initCmd.Dir = projectDir
-	if out, err := initCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("go mod init: %w: %s", err, out)
+	// Write go.mod and go.sum ourselves, so generation needs no Go toolchain
+	// and every project starts from the same tested versions.
+	if err := g.writeFile(g.projectPath("go.mod"), goMod(module, g.config)); err != nil {
+		return err
+	}
+	return g.render(g.projectPath("go.sum"), g.config)
+}
+
+// goMod returns the go.mod of a new project: the direct dependencies of the
+// code config generates and their indirect dependencies, all pinned.
+func goMod(module string, config *Config) string {
+	goVersion := config.GoVersion
+	if olderGo(goVersion, MinGoVersion) {
+		goVersion = MinGoVersion
+	}
+
+	var direct, indirect []string
+	for _, dep := range dependencies {
+		switch {
+		case dep.needed != nil && !dep.needed(config):
+		case dep.direct != nil && dep.direct(config):
+			direct = append(direct, "\t"+dep.path+" "+dep.version+"\n")
+		default:
+			indirect = append(indirect, "\t"+dep.path+" "+dep.version+" // indirect\n")
+		}
+	}
+
+	sort.Strings(direct)
+	sort.Strings(indirect)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "module %s\n\ngo %s\n", module, goVersion)
+	for _, block := range [][]string{direct, indirect} {
+		if len(block) > 0 {
+			b.WriteString("\nrequire (\n" + strings.Join(block, "") + ")\n")
+		}
+	}
+	return b.String()
+}
+
+// mergeModFile brings the project's go.mod or go.sum, whose content for a
+// new project f holds, up to the pinned dependencies of config. The project
+// owns both files, so they are never replaced or three-way merged: missing
+// requirements and checksums are added and older versions raised to the
+// pinned ones, and nothing is removed. A missing go.sum is created. An entry
+// the ledger shows as untouched is updated to the merged content.
+func mergeModFile(fsys FS, f PlannedFile, ledger Ledger, config *Config) (UpgradeFile, []byte, error) {
+	res := UpgradeFile{Path: f.Path}
+	ours, err := fsys.ReadFile(f.Path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && f.Path == "go.sum":
+		// Projects from generator versions that left dependencies to
+		// `go mod tidy` have none.
+		ledger[f.Path] = Sum(f.Content)
+		res.Status = UpgradeAdded
+		return res, f.Content, nil
+	case errors.Is(err, fs.ErrNotExist):
+		res.Status, res.Note = UpgradeSkipped, "deleted in the project"
+		return res, nil, nil
+	case err != nil:
+		return res, nil, err
+	}
+
+	var merged []byte
+	if f.Path == "go.mod" {
+		if merged, err = mergeGoMod(f.Path, ours, config); err != nil {
+			return res, nil, err
+		}
+	} else {
+		merged = mergeGoSum(ours, f.Content)
+	}
+	if bytes.Equal(merged, ours) {
+		res.Status = UpgradeUnchanged
+		return res, nil, nil
+	}
+	if ledger.Untouched(f.Path, ours) {
+		ledger[f.Path] = Sum(merged)
+	}
+	res.Status = UpgradeEdited
+	return res, merged, nil
+}
+
+// mergeGoMod adds the dependencies config needs to a go.mod, at their pinned
+// versions. Requirements at the pinned version or newer are kept as they are.
+func mergeGoMod(path string, data []byte, config *Config) ([]byte, error) {
+	f, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var reqs []*modfile.Require
+	have := map[string]*modfile.Require{}
+	for _, r := range f.Require {
+		req := &modfile.Require{Mod: r.Mod, Indirect: r.Indirect}
+		reqs = append(reqs, req)
+		have[r.Mod.Path] = req
+	}
+	for _, dep := range dependencies {
+		if dep.needed != nil && !dep.needed(config) {
+			continue
+		}
+		direct := dep.direct != nil && dep.direct(config)
+		req, ok := have[dep.path]
+		if !ok {
+			reqs = append(reqs, &modfile.Require{Mod: module.Version{Path: dep.path, Version: dep.version}, Indirect: !direct})
+			continue
+		}
+		if semver.Compare(req.Mod.Version, dep.version) < 0 {
+			req.Mod.Version = dep.version
+		}
+		if direct {
+			req.Indirect = false
+		}
 	}
 
-	// Don't run templ generate or go mod tidy here - user runs 'make setup' to do that
-	return nil
+	f.SetRequireSeparateIndirect(reqs)
+	f.Cleanup()
+	return f.Format()
+}
+
+// mergeGoSum adds the lines of sum missing from a go.sum, sorted the way the
+// go command writes them.
+func mergeGoSum(data, sum []byte) []byte {
+	lines := strings.SplitAfter(string(data), "\n")
+	have := map[string]bool{}
+	for _, line := range lines {
+		have[strings.TrimSuffix(line, "\n")] = true
+	}
+	added := false
+	for _, line := range strings.Split(string(sum), "\n") {
+		if line != "" && !have[line] {
+			lines = append(lines, line+"\n")
+			have[line] = true
+			added = true
+		}
+	}
+	if !added {
+		return data
+	}
+
+	var out []string
+	for _, line := range lines {
+		if line = strings.TrimSuffix(line, "\n"); line != "" {
+			out = append(out, line)
+		}
+	}
+	sort.Strings(out)
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+// olderGo reports whether Go version a ("1.22", "1.22.3") is older than b.
+// Versions that don't parse are not older.
+func olderGo(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, err1 := strconv.Atoi(as[i])
+		y, err2 := strconv.Atoi(bs[i])
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	return len(as) < len(bs)
 }
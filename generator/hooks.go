@@ -0,0 +1,175 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+)
+
+// StepWrite is the step name hooks use for writing the rendered files to the
+// target FS, which follows every generation step. Hooks after it see the
+// finished project.
+const StepWrite = "write"
+
+// HookPhase says whether a hook runs before or after its step.
+type HookPhase string
+
+const (
+	HookBefore HookPhase = "before"
+	HookAfter  HookPhase = "after"
+)
+
+// HookContext describes the step a hook runs around.
+type HookContext struct {
+	Step   string
+	Phase  HookPhase
+	Config *Config
+	DryRun bool
+	// Files are the files rendered so far, in order. Until the write step,
+	// hooks may change their Content; after it, Action says what was done.
+	Files []PlannedFile
+	// Dir is the project directory after the write step when the project
+	// was written to disk, and empty otherwise.
+	Dir string
+}
+
+// Hook is called before or after a generation step. An error aborts
+// Generate; hooks before the write step abort it before anything is written.
+type Hook func(*HookContext) error
+
+// HookCommand is a shell command run before or after a generation step,
+// recorded in the manifest. Exactly one of Before and After names the step:
+// a built-in step such as "handlers", a pack step as "pack/step", or
+// StepWrite.
+type HookCommand struct {
+	Before string `yaml:"before,omitempty" json:"before,omitempty"`
+	After  string `yaml:"after,omitempty" json:"after,omitempty"`
+	Run    string `yaml:"run" json:"run"`
+}
+
+// Phase returns the phase and step of the command.
+func (h HookCommand) Phase() (HookPhase, string) {
+	if h.Before != "" {
+		return HookBefore, h.Before
+	}
+	return HookAfter, h.After
+}
+
+type registeredHook struct {
+	phase HookPhase
+	step  string
+	fn    Hook
+}
+
+// WithHook registers fn to run before or after the named step. Hooks run in
+// the order they were registered, followed by the Config.Hooks commands.
+func WithHook(phase HookPhase, step string, fn Hook) Option {
+	return func(g *Generator) {
+		g.hooks = append(g.hooks, registeredHook{phase, step, fn})
+	}
+}
+
+// CommandHook returns a hook that runs command with sh -c, with its output on
+// stderr. After the write step of a project written to disk it runs in the
+// project directory; otherwise it runs in a temporary copy of the files
+// rendered so far. The step and phase are in $GOTH_STEP and $GOTH_PHASE.
+// Commands are not run on dry runs.
+func CommandHook(command string) Hook {
+	return func(ctx *HookContext) error {
+		if ctx.DryRun {
+			return nil
+		}
+		dir := ctx.Dir
+		if dir == "" {
+			tmp, err := os.MkdirTemp("", "goth-hook-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmp)
+			for _, f := range ctx.Files {
+				if err := DirFS(tmp).WriteFile(f.Path, f.Content); err != nil {
+					return err
+				}
+			}
+			dir = tmp
+		}
+
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = dir
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(),
+			"GOTH_STEP="+ctx.Step,
+			"GOTH_PHASE="+string(ctx.Phase),
+			"GOTH_PROJECT_NAME="+ctx.Config.Name,
+		)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", command, err)
+		}
+		return nil
+	}
+}
+
+// UnknownStepError is returned by Generate when a hook names a step that
+// does not exist. Nothing has been rendered.
+type UnknownStepError struct {
+	Phase HookPhase
+	Step  string
+}
+
+func (e *UnknownStepError) Error() string {
+	return fmt.Sprintf("%s hook on unknown step %q", e.Phase, e.Step)
+}
+
+// checkHooks rejects hooks on steps that do not exist, before anything is
+// rendered, with an *UnknownStepError.
+func (g *Generator) checkHooks(steps []generateStep) error {
+	known := map[string]bool{StepWrite: true}
+	for _, s := range steps {
+		known[s.name] = true
+	}
+	for _, h := range g.allHooks() {
+		if !known[h.step] {
+			return &UnknownStepError{Phase: h.phase, Step: h.step}
+		}
+	}
+	return nil
+}
+
+// allHooks returns the registered hooks followed by the Config.Hooks
+// commands. Projects reproduced from a manifest run no hooks.
+func (g *Generator) allHooks() []registeredHook {
+	if g.reproducing {
+		return nil
+	}
+	hooks := slices.Clone(g.hooks)
+	for _, h := range g.config.Hooks {
+		phase, step := h.Phase()
+		hooks = append(hooks, registeredHook{phase, step, CommandHook(h.Run)})
+	}
+	return hooks
+}
+
+// runHooks runs the hooks for phase of step.
+func (g *Generator) runHooks(phase HookPhase, step string) error {
+	for _, h := range g.allHooks() {
+		if h.phase != phase || h.step != step {
+			continue
+		}
+		ctx := &HookContext{
+			Step:   step,
+			Phase:  phase,
+			Config: g.config,
+			DryRun: g.dryRun,
+			Files:  g.files,
+		}
+		if d, ok := g.fs.(*diskFS); ok && step == StepWrite && phase == HookAfter && !g.dryRun {
+			ctx.Dir = d.root
+		}
+		if err := h.fn(ctx); err != nil {
+			return fmt.Errorf("%s hook: %w", phase, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package generator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnknownStepHook(t *testing.T) {
+	config := Config{Name: "app", Module: "example.com/app", Port: "8080", DBDriver: "sqlite"}
+	fsys := NewMemFS()
+	hook := func(*HookContext) error { return nil }
+	err := New(&config, WithFS(fsys), WithHook(HookAfter, "nosuch", hook)).Generate()
+
+	var stepErr *UnknownStepError
+	if !errors.As(err, &stepErr) {
+		t.Fatalf("Generate = %v, want *UnknownStepError", err)
+	}
+	if stepErr.Phase != HookAfter || stepErr.Step != "nosuch" {
+		t.Errorf("UnknownStepError = %+v, want after hook on nosuch", stepErr)
+	}
+	if paths := fsys.Paths(); len(paths) != 0 {
+		t.Errorf("wrote %v before rejecting the hook", paths)
+	}
+}
@@ -0,0 +1,149 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// LedgerFile records the SHA-256 of every file the generator wrote, in the
+// format of sha256sum, so later commands can tell generated files the user
+// has edited from ones they have not.
+const LedgerFile = "goth.sum"
+
+// Ledger maps project-relative paths to the hex SHA-256 of the content the
+// generator wrote there.
+type Ledger map[string]string
+
+// Sum returns the hex SHA-256 of data as recorded in a Ledger.
+func Sum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ReadLedger reads the ledger of the project in fsys. A project without one
+// has an empty ledger.
+func ReadLedger(fsys FS) (Ledger, error) {
+	data, err := fsys.ReadFile(LedgerFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Ledger{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l := Ledger{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		sum, path, ok := strings.Cut(line, "  ")
+		if !ok || len(sum) != sha256.Size*2 {
+			return nil, fmt.Errorf("%s:%d: malformed line", LedgerFile, n)
+		}
+		l[path] = sum
+	}
+	return l, scanner.Err()
+}
+
+// Marshal renders the ledger as written to goth.sum, sorted by path.
+func (l Ledger) Marshal() []byte {
+	paths := make([]string, 0, len(l))
+	for path := range l {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "%s  %s\n", l[path], path)
+	}
+	return buf.Bytes()
+}
+
+// Untouched reports whether content is exactly what the generator last wrote
+// to path.
+func (l Ledger) Untouched(path string, content []byte) bool {
+	sum, ok := l[path]
+	return ok && sum == Sum(content)
+}
+
+// FileState is the state of a generated file relative to the ledger.
+type FileState string
+
+const (
+	StateUntouched FileState = "untouched"
+	StateModified  FileState = "modified"
+	StateDeleted   FileState = "deleted"
+)
+
+// FileStatus is the state of one file recorded in the ledger.
+type FileStatus struct {
+	Path  string
+	State FileState
+}
+
+// Status compares every file in the ledger of the project in fsys with what
+// is on disk, sorted by path.
+func Status(fsys FS) ([]FileStatus, error) {
+	l, err := ReadLedger(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if len(l) == 0 {
+		return nil, fmt.Errorf("no %s in project: %w", LedgerFile, fs.ErrNotExist)
+	}
+
+	var statuses []FileStatus
+	for path, sum := range l {
+		st := FileStatus{Path: path, State: StateUntouched}
+		data, err := fsys.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			st.State = StateDeleted
+		case err != nil:
+			return nil, err
+		case Sum(data) != sum:
+			st.State = StateModified
+		}
+		statuses = append(statuses, st)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Path < statuses[j].Path })
+	return statuses, nil
+}
+
+// planLedger adds the project's ledger, updated with the files about to be
+// written, to the rendered files, so dry runs list it like any other file.
+// Skipped files keep their previous entry. The ledger is the generator's
+// own record, so a changed one is overwritten rather than a conflict.
+func (g *Generator) planLedger(l Ledger) error {
+	for _, f := range g.files {
+		switch f.Action {
+		case ActionCreate, ActionUnchanged, ActionOverwrite, ActionBackup:
+			l[f.Path] = Sum(f.Content)
+		}
+	}
+
+	f := PlannedFile{Path: LedgerFile, Step: g.step, Content: l.Marshal(), Action: ActionCreate}
+	old, err := g.fs.ReadFile(LedgerFile)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	case bytes.Equal(old, f.Content):
+		f.Action = ActionUnchanged
+	default:
+		f.Action = ActionOverwrite
+		f.Diff = UnifiedDiff(LedgerFile, old, f.Content)
+	}
+	g.files = append(g.files, f)
+	return nil
+}
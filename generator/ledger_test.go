@@ -0,0 +1,178 @@
+package generator
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestStatus(t *testing.T) {
+	config := Config{Name: "app", Module: "example.com/app", Port: "8080", DBDriver: "sqlite"}
+	fsys := generateMem(t, config)
+	if err := fsys.WriteFile("README.md", []byte("# Edited\n")); err != nil {
+		t.Fatal(err)
+	}
+	// MemFS has no Remove; copy the project without .gitignore instead.
+	trimmed := NewMemFS()
+	for name, data := range memFiles(t, fsys) {
+		if name == ".gitignore" {
+			continue
+		}
+		if err := trimmed.WriteFile(name, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	statuses, err := Status(trimmed)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	states := map[string]FileState{}
+	for _, st := range statuses {
+		states[st.Path] = st.State
+	}
+	for path, want := range map[string]FileState{
+		"README.md":          StateModified,
+		".gitignore":         StateDeleted,
+		"cmd/server/main.go": StateUntouched,
+		ManifestFile:         StateUntouched,
+	} {
+		if states[path] != want {
+			t.Errorf("%s: %q, want %q", path, states[path], want)
+		}
+	}
+	if _, ok := states[LedgerFile]; ok {
+		t.Errorf("the ledger lists itself")
+	}
+}
+
+func TestStatusWithoutLedger(t *testing.T) {
+	_, err := Status(NewMemFS())
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Status: err = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestReadLedger(t *testing.T) {
+	sum := Sum([]byte("package main\n"))
+	tests := []struct {
+		name    string
+		content string
+		want    Ledger
+		err     string
+	}{
+		{name: "missing", want: Ledger{}},
+		{name: "empty", content: "", want: Ledger{}},
+		{name: "entries", content: sum + "  main.go\n\n" + sum + "  cmd/server/main.go\n", want: Ledger{"main.go": sum, "cmd/server/main.go": sum}},
+		{name: "path with spaces", content: sum + "  docs/read me.md\n", want: Ledger{"docs/read me.md": sum}},
+		{name: "one space", content: sum + " main.go\n", err: "goth.sum:1: malformed line"},
+		{name: "short sum", content: "abc123  main.go\n", err: "goth.sum:1: malformed line"},
+		{name: "second line", content: sum + "  main.go\nnot a ledger line\n", err: "goth.sum:2: malformed line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := NewMemFS()
+			if tt.name != "missing" {
+				if err := fsys.WriteFile(LedgerFile, []byte(tt.content)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := ReadLedger(fsys)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("ReadLedger: err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadLedger: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ReadLedger = %v, want %v", got, tt.want)
+			}
+			for path, sum := range tt.want {
+				if got[path] != sum {
+					t.Errorf("ReadLedger[%q] = %q, want %q", path, got[path], sum)
+				}
+			}
+		})
+	}
+}
+
+func TestLedgerMarshalRoundTrip(t *testing.T) {
+	l := Ledger{"b.go": Sum([]byte("b")), "a.go": Sum([]byte("a"))}
+	data := l.Marshal()
+	if !strings.HasPrefix(string(data), l["a.go"]+"  a.go\n") {
+		t.Errorf("Marshal is not sorted by path:\n%s", data)
+	}
+	fsys := NewMemFS()
+	if err := fsys.WriteFile(LedgerFile, data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadLedger(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["a.go"] != l["a.go"] || got["b.go"] != l["b.go"] {
+		t.Errorf("round trip = %v, want %v", got, l)
+	}
+}
+
+func TestRecordLedgerKeepsSkippedEntries(t *testing.T) {
+	config := Config{Name: "app", Module: "example.com/app", Port: "8080", DBDriver: "sqlite"}
+	fsys := generateMem(t, config)
+	ledger, err := ReadLedger(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.WriteFile("README.md", []byte("# Edited\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := New(&config, WithFS(fsys), WithConflictStrategy(ConflictSkip)).Generate(); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	after, err := ReadLedger(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after["README.md"] != ledger["README.md"] {
+		t.Errorf("the ledger entry of a skipped file changed")
+	}
+}
+
+func TestDryRunPlansLedger(t *testing.T) {
+	config := Config{Name: "app", Module: "example.com/app", Port: "8080", DBDriver: "sqlite"}
+	planned := func(fsys FS) PlannedFile {
+		t.Helper()
+		g := New(&config, WithFS(fsys), WithDryRun())
+		if err := g.Generate(); err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		for _, f := range g.Files() {
+			if f.Path == LedgerFile {
+				return f
+			}
+		}
+		t.Fatalf("the dry run does not list %s", LedgerFile)
+		return PlannedFile{}
+	}
+
+	empty := NewMemFS()
+	if f := planned(empty); f.Action != ActionCreate {
+		t.Errorf("%s in an empty directory: %s, want %s", LedgerFile, f.Action, ActionCreate)
+	}
+	if len(empty.Paths()) != 0 {
+		t.Errorf("the dry run wrote %v", empty.Paths())
+	}
+
+	fsys := generateMem(t, config)
+	f := planned(fsys)
+	if f.Action != ActionUnchanged {
+		t.Errorf("%s of a generated project: %s, want %s", LedgerFile, f.Action, ActionUnchanged)
+	}
+	if got := readString(t, fsys, LedgerFile); got != string(f.Content) {
+		t.Errorf("the planned %s differs from the one written", LedgerFile)
+	}
+}
@@ -0,0 +1,323 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// editMain inserts what config needs into a cmd/server/main.go the user may
+// have edited. The syntax tree is only used to find where things go; the
+// insertions are made as text so the user's code and comments are left as
+// they were, and the result is gofmt'ed.
+func editMain(src []byte, config *Config) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, mainGoPath, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	e := &mainEditor{fset: fset, file: file, src: src}
+	if err := e.edit(config); err != nil {
+		return nil, fmt.Errorf("could not edit %s: %w", mainGoPath, err)
+	}
+
+	out, err := format.Source(e.apply())
+	if err != nil {
+		return nil, fmt.Errorf("could not edit %s: %w", mainGoPath, err)
+	}
+	return out, nil
+}
+
+type mainEditor struct {
+	fset  *token.FileSet
+	file  *ast.File
+	src   []byte
+	body  []ast.Stmt
+	edits []textEdit
+}
+
+// textEdit inserts or replaces text at a byte offset of the source.
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+func (e *mainEditor) edit(config *Config) error {
+	var main *ast.FuncDecl
+	for _, decl := range e.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "main" && fn.Recv == nil {
+			main = fn
+		}
+	}
+	if main == nil || main.Body == nil {
+		return fmt.Errorf("no func main")
+	}
+	e.body = main.Body.List
+
+	newHandler := e.findStmt(func(call *ast.CallExpr) bool { return isCall(call, "handlers", "NewHandler") })
+	if newHandler == nil {
+		return fmt.Errorf("no call to handlers.NewHandler")
+	}
+	call := stmtCall(newHandler)
+	if len(call.Args) == 0 {
+		return fmt.Errorf("handlers.NewHandler has no arguments")
+	}
+
+	// Services, constructed before the handler that uses them.
+	withSessionStore := config.WithSessions
+	withUserService := config.WithAuth || config.WithUsers
+	if withSessionStore {
+		e.addImport(config.Module + "/internal/session")
+		e.addService(newHandler, "sessionStore", "session", "NewStore")
+	}
+	if withUserService {
+		e.addImport(config.Module + "/internal/user")
+		e.addService(newHandler, "userService", "user", "NewService")
+	}
+
+	// Arguments matching the NewHandler signature in internal/handlers.
+	args := []string{e.text(call.Args[0])}
+	if withSessionStore {
+		args = append(args, "sessionStore")
+	}
+	if withUserService {
+		args = append(args, "userService")
+	}
+	current := make([]string, len(call.Args))
+	for i, arg := range call.Args {
+		current[i] = e.text(arg)
+	}
+	if strings.Join(current, ", ") != strings.Join(args, ", ") {
+		e.replace(call.Args[0].Pos(), call.Rparen, strings.Join(args, ", "))
+	}
+
+	// Middleware, in the order the generator emits it: Session, then Auth,
+	// both before nosurf.
+	csrf := e.findStmt(func(call *ast.CallExpr) bool { return isCall(call, "nosurf", "New") })
+	if csrf == nil {
+		return fmt.Errorf("no call to nosurf.New to place middleware before")
+	}
+	handlerVar := "handler"
+	if assign, ok := csrf.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 {
+		handlerVar = e.text(assign.Lhs[0])
+	}
+	auth := e.findStmt(func(call *ast.CallExpr) bool { return isCall(call, "middleware", "Auth") })
+	if config.WithSessions && e.findStmt(func(call *ast.CallExpr) bool { return isCall(call, "middleware", "Session") }) == nil {
+		before := csrf
+		if auth != nil {
+			before = auth
+		}
+		e.insertBefore(before, fmt.Sprintf("%s = middleware.Session(sessionStore, %s)", handlerVar, handlerVar))
+	}
+	if config.WithAuth && auth == nil {
+		e.insertBefore(csrf, fmt.Sprintf("%s = middleware.Auth(%s)", handlerVar, handlerVar))
+	}
+
+	// Routes, after the existing ones.
+	hVar := "h"
+	if assign, ok := newHandler.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 {
+		hVar = e.text(assign.Lhs[0])
+	}
+	var lastRoute, home ast.Stmt
+	routerVar := ""
+	for _, stmt := range e.body {
+		call := stmtCall(stmt)
+		if call == nil {
+			continue
+		}
+		if isCall(call, "httprouter", "New") {
+			if assign, ok := stmt.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 {
+				routerVar = e.text(assign.Lhs[0])
+			}
+			continue
+		}
+		if path, ok := e.routePath(call, routerVar); ok {
+			lastRoute = stmt
+			if path == "/" {
+				home = stmt
+			}
+		}
+	}
+	if lastRoute == nil {
+		return fmt.Errorf("no router.GET/POST route registrations to add routes after")
+	}
+	if config.WithAuth && !e.hasRoute(routerVar, "/login") {
+		after := lastRoute
+		if home != nil {
+			after = home
+		}
+		e.insertAfter(after, strings.ReplaceAll(strings.ReplaceAll(`router.GET("/login", h.Login)
+router.POST("/login", h.HandleLogin)
+router.GET("/register", h.Register)
+router.POST("/register", h.HandleRegister)
+router.GET("/logout", h.HandleLogout)
+router.POST("/logout", h.HandleLogout)`, "router.", routerVar+"."), "h.", hVar+"."))
+	}
+	if config.WithUsers && !e.hasRoute(routerVar, "/users") {
+		e.insertAfter(lastRoute, strings.ReplaceAll(strings.ReplaceAll(`router.GET("/users", h.ListUsers)
+router.GET("/users/:id", h.GetUser)`, "router.", routerVar+"."), "h.", hVar+"."))
+	}
+	return nil
+}
+
+// addImport adds path to the parenthesized import block if it is missing.
+func (e *mainEditor) addImport(path string) {
+	for _, imp := range e.file.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err == nil && p == path {
+			return
+		}
+	}
+	for _, decl := range e.file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && gen.Rparen.IsValid() {
+			e.insert(gen.Rparen, "\t"+strconv.Quote(path)+"\n")
+			return
+		}
+	}
+	// A file without an import block still has a package clause.
+	e.insert(e.file.Name.End(), "\n\nimport "+strconv.Quote(path))
+}
+
+// addService constructs name with pkg.constructor(db) unless main already
+// does. Older projects declare the variable up front and assign it.
+func (e *mainEditor) addService(before ast.Stmt, name, pkg, constructor string) {
+	if e.findStmt(func(call *ast.CallExpr) bool { return isCall(call, pkg, constructor) }) != nil {
+		return
+	}
+	for _, stmt := range e.body {
+		decl, ok := stmt.(*ast.DeclStmt)
+		if !ok {
+			continue
+		}
+		gen, ok := decl.Decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if vs, ok := spec.(*ast.ValueSpec); ok && len(vs.Names) == 1 && vs.Names[0].Name == name && len(vs.Values) == 0 {
+				e.insertAfter(stmt, fmt.Sprintf("%s = %s.%s(db)", name, pkg, constructor))
+				return
+			}
+		}
+	}
+	e.insertBefore(before, fmt.Sprintf("%s := %s.%s(db)", name, pkg, constructor))
+}
+
+// findStmt returns the first statement of main whose call matches.
+func (e *mainEditor) findStmt(match func(*ast.CallExpr) bool) ast.Stmt {
+	for _, stmt := range e.body {
+		if call := stmtCall(stmt); call != nil && match(call) {
+			return stmt
+		}
+	}
+	return nil
+}
+
+// routePath returns the path of a route registration such as
+// router.GET("/login", h.Login).
+func (e *mainEditor) routePath(call *ast.CallExpr, routerVar string) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || e.text(sel.X) != routerVar || len(call.Args) == 0 {
+		return "", false
+	}
+	arg := 0
+	switch sel.Sel.Name {
+	case "GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS":
+	case "Handle", "Handler", "HandlerFunc":
+		arg = 1 // method first
+	default:
+		return "", false
+	}
+	if len(call.Args) <= arg {
+		return "", false
+	}
+	lit, ok := call.Args[arg].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	path, err := strconv.Unquote(lit.Value)
+	return path, err == nil
+}
+
+// hasRoute reports whether main registers path or a path below it.
+func (e *mainEditor) hasRoute(routerVar, path string) bool {
+	for _, stmt := range e.body {
+		if call := stmtCall(stmt); call != nil {
+			if p, ok := e.routePath(call, routerVar); ok && (p == path || strings.HasPrefix(p, path+"/")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stmtCall returns the call made by an expression statement such as
+// router.GET(...) or an assignment such as h := handlers.NewHandler(...).
+func stmtCall(stmt ast.Stmt) *ast.CallExpr {
+	var expr ast.Expr
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		expr = s.X
+	case *ast.AssignStmt:
+		if len(s.Rhs) == 1 {
+			expr = s.Rhs[0]
+		}
+	}
+	call, _ := expr.(*ast.CallExpr)
+	return call
+}
+
+// isCall reports whether call is pkg.name(...).
+func isCall(call *ast.CallExpr, pkg, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == pkg
+}
+
+func (e *mainEditor) offset(pos token.Pos) int {
+	return e.fset.Position(pos).Offset
+}
+
+func (e *mainEditor) text(node ast.Node) string {
+	return string(e.src[e.offset(node.Pos()):e.offset(node.End())])
+}
+
+func (e *mainEditor) insert(pos token.Pos, text string) {
+	off := e.offset(pos)
+	e.edits = append(e.edits, textEdit{start: off, end: off, text: text})
+}
+
+func (e *mainEditor) insertBefore(stmt ast.Stmt, line string) {
+	e.insert(stmt.Pos(), line+"\n\t")
+}
+
+func (e *mainEditor) insertAfter(stmt ast.Stmt, lines string) {
+	e.insert(stmt.End(), "\n\t"+strings.ReplaceAll(lines, "\n", "\n\t"))
+}
+
+func (e *mainEditor) replace(start, end token.Pos, text string) {
+	e.edits = append(e.edits, textEdit{start: e.offset(start), end: e.offset(end), text: text})
+}
+
+// apply returns the source with the edits made. Insertions at the same
+// offset keep the order they were made in.
+func (e *mainEditor) apply() []byte {
+	sort.SliceStable(e.edits, func(i, j int) bool { return e.edits[i].start < e.edits[j].start })
+	var out bytes.Buffer
+	last := 0
+	for _, edit := range e.edits {
+		out.Write(e.src[last:edit.start])
+		out.WriteString(edit.text)
+		last = edit.end
+	}
+	out.Write(e.src[last:])
+	return out.Bytes()
+}
@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+// editedMain is a hand-edited cmd/server/main.go without auth, users or
+// sessions: the handler, router and middleware variables are renamed.
+const editedMain = `package main
+
+import (
+	"log"
+	"net/http"
+
+	"example.com/app/internal/database"
+	"example.com/app/internal/handlers"
+	"example.com/app/internal/middleware"
+	"github.com/julienschmidt/httprouter"
+	"github.com/justinas/nosurf"
+)
+
+func main() {
+	db, err := database.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	app := handlers.NewHandler("app")
+
+	mux := httprouter.New()
+	mux.GET("/health", handlers.HealthCheck)
+
+	// Our own middleware stack.
+	stack := middleware.Logging(mux)
+	stack = nosurf.New(stack)
+
+	mux.GET("/", app.Home)
+	mux.GET("/about", app.Home)
+
+	log.Fatal(http.ListenAndServe(":8080", stack))
+}
+`
+
+func TestEditMain(t *testing.T) {
+	config := &Config{Module: "example.com/app", WithAuth: true, WithUsers: true, WithSessions: true}
+	out, err := editMain([]byte(editedMain), config)
+	if err != nil {
+		t.Fatalf("editMain: %v", err)
+	}
+	for _, want := range []string{
+		`"example.com/app/internal/session"`,
+		`"example.com/app/internal/user"`,
+		"sessionStore := session.NewStore(db)\n\tuserService := user.NewService(db)\n\tapp := handlers.NewHandler(\"app\", sessionStore, userService)",
+		"stack = middleware.Session(sessionStore, stack)\n\tstack = middleware.Auth(stack)\n\tstack = nosurf.New(stack)",
+		"mux.GET(\"/\", app.Home)\n\tmux.GET(\"/login\", app.Login)",
+		"mux.GET(\"/about\", app.Home)\n\tmux.GET(\"/users\", app.ListUsers)\n\tmux.GET(\"/users/:id\", app.GetUser)",
+		"// Our own middleware stack.",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("edited main.go does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "router.GET") || strings.Contains(string(out), "h.Login") {
+		t.Errorf("edited main.go uses the default variable names:\n%s", out)
+	}
+}
+
+func TestEditMainVarBlock(t *testing.T) {
+	src := strings.Replace(editedMain, "\tapp := handlers.NewHandler(\"app\")", `	var (
+		sessionStore *session.Store
+		verbose      bool
+	)
+	_ = verbose
+	app := handlers.NewHandler("app")`, 1)
+	out, err := editMain([]byte(src), &Config{Module: "example.com/app", WithSessions: true})
+	if err != nil {
+		t.Fatalf("editMain: %v", err)
+	}
+	if !strings.Contains(string(out), "\t)\n\tsessionStore = session.NewStore(db)\n") {
+		t.Errorf("the declared sessionStore is not assigned after the var block:\n%s", out)
+	}
+	if strings.Contains(string(out), "sessionStore :=") {
+		t.Errorf("sessionStore is declared twice:\n%s", out)
+	}
+}
+
+func TestEditMainAlreadyPresent(t *testing.T) {
+	config := &Config{Module: "example.com/app", WithSessions: true}
+	once, err := editMain([]byte(editedMain), config)
+	if err != nil {
+		t.Fatalf("editMain: %v", err)
+	}
+	twice, err := editMain(once, config)
+	if err != nil {
+		t.Fatalf("editMain again: %v", err)
+	}
+	if string(twice) != string(once) {
+		t.Errorf("editing main.go that already has sessions changed it:\n%s", twice)
+	}
+
+	// The generated main.go for the config needs no edits either.
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	files, err := Pristine(Config{Name: "app", Module: "example.com/app", Port: "8080", DBDriver: "sqlite", WithAuth: true, WithUsers: true, WithSessions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated := files[mainGoPath]
+	out, err := editMain(generated, &Config{Module: "example.com/app", WithAuth: true, WithUsers: true, WithSessions: true})
+	if err != nil {
+		t.Fatalf("editMain: %v", err)
+	}
+	if string(out) != string(generated) {
+		t.Errorf("editing the generated main.go changed it:\n%s", out)
+	}
+}
+
+func TestEditMainWithoutNosurf(t *testing.T) {
+	src := strings.Replace(editedMain, "\tstack = nosurf.New(stack)\n", "", 1)
+	_, err := editMain([]byte(src), &Config{Module: "example.com/app", WithSessions: true})
+	if err == nil || !strings.Contains(err.Error(), "nosurf.New") {
+		t.Errorf("editMain without nosurf: err = %v, want one naming nosurf.New", err)
+	}
+}
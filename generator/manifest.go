@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the generator version recorded in every manifest.
+const Version = "1.2.0"
+
+// ManifestFile is the manifest written at the root of every generated project.
+const ManifestFile = "goth.yaml"
+
+const manifestHeader = `# Written by goth-generate. Later goth-generate commands read this file to
+# learn how the project was generated; keep it under version control.
+`
+
+// Manifest is the persisted record of how a project was generated.
+type Manifest struct {
+	Config `yaml:",inline"`
+}
+
+// LoadManifest reads the manifest of the project in dir. The returned
+// Config has OutputDir set to dir.
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", ManifestFile, err)
+	}
+	m.OutputDir = dir
+	return &m, nil
+}
+
+// Marshal renders the manifest as written to goth.yaml.
+func (m *Manifest) Marshal() ([]byte, error) {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(manifestHeader), data...), nil
+}
+
+func (g *Generator) generateManifest() error {
+	data, err := (&Manifest{Config: *g.config}).Marshal()
+	if err != nil {
+		return err
+	}
+	return g.writeFile(g.projectPath(ManifestFile), string(data))
+}
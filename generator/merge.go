@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+)
+
+// MergeLabels name the three sides in conflict markers.
+type MergeLabels struct {
+	Ours, Base, Theirs string
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs, line
+// by line, the way diff3 does. Regions changed on only one side take that
+// side's lines; regions changed identically on both sides are taken once;
+// other regions are written with conflict markers. It returns the merged
+// content and the number of conflicting regions.
+func Merge3(base, ours, theirs []byte, labels MergeLabels) ([]byte, int) {
+	b, o, t := splitLines(string(base)), splitLines(string(ours)), splitLines(string(theirs))
+	toOurs, toTheirs := lineMatches(b, o), lineMatches(b, t)
+
+	var out strings.Builder
+	conflicts := 0
+	i, j, k := 0, 0, 0
+	for i < len(b) || j < len(o) || k < len(t) {
+		// A line unchanged on both sides.
+		if i < len(b) && toOurs[i] == j && toTheirs[i] == k {
+			out.WriteString(b[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		// Otherwise the chunk runs up to the next base line kept by both sides.
+		i2, j2, k2 := len(b), len(o), len(t)
+		for n := i; n < len(b); n++ {
+			if toOurs[n] >= 0 && toTheirs[n] >= 0 {
+				i2, j2, k2 = n, toOurs[n], toTheirs[n]
+				break
+			}
+		}
+		baseChunk, oursChunk, theirsChunk := b[i:i2], o[j:j2], t[k:k2]
+
+		switch {
+		case equalLines(oursChunk, baseChunk):
+			writeLines(&out, theirsChunk, false)
+		case equalLines(theirsChunk, baseChunk), equalLines(oursChunk, theirsChunk):
+			writeLines(&out, oursChunk, false)
+		default:
+			conflicts++
+			out.WriteString("<<<<<<< " + labels.Ours + "\n")
+			writeLines(&out, oursChunk, true)
+			out.WriteString("||||||| " + labels.Base + "\n")
+			writeLines(&out, baseChunk, true)
+			out.WriteString("=======\n")
+			writeLines(&out, theirsChunk, true)
+			out.WriteString(">>>>>>> " + labels.Theirs + "\n")
+		}
+		i, j, k = i2, j2, k2
+	}
+	return []byte(out.String()), conflicts
+}
+
+// lineMatches maps each line of a to the index of the line of b it is
+// matched with by the longest common subsequence, or -1 if it was deleted.
+func lineMatches(a, b []string) []int {
+	matches := make([]int, len(a))
+	i, j := 0, 0
+	for _, op := range diffLines(a, b) {
+		switch op.kind {
+		case '=':
+			matches[i] = j
+			i, j = i+1, j+1
+		case '-':
+			matches[i] = -1
+			i++
+		case '+':
+			j++
+		}
+	}
+	return matches
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeLines writes lines. With terminate set, a final line without a
+// newline gets one, so a following conflict marker starts on its own line.
+func writeLines(out *strings.Builder, lines []string, terminate bool) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+	if terminate && len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		out.WriteString("\n")
+	}
+}
+
+// hasConflictMarkers reports whether content still contains merge markers.
+func hasConflictMarkers(content []byte) bool {
+	return bytes.Contains(content, []byte("\n<<<<<<< ")) || bytes.HasPrefix(content, []byte("<<<<<<< "))
+}
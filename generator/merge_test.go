@@ -0,0 +1,118 @@
+package generator
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	labels := MergeLabels{Ours: "ours", Base: "base", Theirs: "theirs"}
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		conflicts          int
+	}{
+		{
+			name:   "edited in ours only",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "edited in theirs only",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nC\n",
+			want:   "a\nb\nC\n",
+		},
+		{
+			name:   "separate edits on both sides",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "same edit on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:      "conflicting edits",
+			base:      "a\nb\nc\n",
+			ours:      "a\nours\nc\n",
+			theirs:    "a\ntheirs\nc\n",
+			want:      "a\n<<<<<<< ours\nours\n||||||| base\nb\n=======\ntheirs\n>>>>>>> theirs\nc\n",
+			conflicts: 1,
+		},
+		{
+			name:      "deleted in ours, edited in theirs",
+			base:      "a\nb\nc\n",
+			ours:      "a\nc\n",
+			theirs:    "a\nB\nc\n",
+			want:      "a\n<<<<<<< ours\n||||||| base\nb\n=======\nB\n>>>>>>> theirs\nc\n",
+			conflicts: 1,
+		},
+		{
+			name:      "different inserts at the same point",
+			base:      "a\nc\n",
+			ours:      "a\nours\nc\n",
+			theirs:    "a\ntheirs\nc\n",
+			want:      "a\n<<<<<<< ours\nours\n||||||| base\n=======\ntheirs\n>>>>>>> theirs\nc\n",
+			conflicts: 1,
+		},
+		{
+			name:   "same insert at the same point",
+			base:   "a\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:      "empty base",
+			base:      "",
+			ours:      "ours\n",
+			theirs:    "theirs\n",
+			want:      "<<<<<<< ours\nours\n||||||| base\n=======\ntheirs\n>>>>>>> theirs\n",
+			conflicts: 1,
+		},
+		{
+			name:   "empty base, same content on both sides",
+			base:   "",
+			ours:   "a\n",
+			theirs: "a\n",
+			want:   "a\n",
+		},
+		{
+			name:   "no trailing newline, edited in theirs",
+			base:   "a\nb",
+			ours:   "a\nb",
+			theirs: "a\nc",
+			want:   "a\nc",
+		},
+		{
+			name:   "trailing newline added in ours",
+			base:   "a\nb",
+			ours:   "a\nb\n",
+			theirs: "a\nb",
+			want:   "a\nb\n",
+		},
+		{
+			name:      "no trailing newline, conflicting edits",
+			base:      "a\nb",
+			ours:      "a\nours",
+			theirs:    "a\ntheirs",
+			want:      "a\n<<<<<<< ours\nours\n||||||| base\nb\n=======\ntheirs\n>>>>>>> theirs\n",
+			conflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3([]byte(tt.base), []byte(tt.ours), []byte(tt.theirs), labels)
+			if string(got) != tt.want || conflicts != tt.conflicts {
+				t.Errorf("Merge3 = %q, %d conflict(s); want %q, %d", got, conflicts, tt.want, tt.conflicts)
+			}
+		})
+	}
+}
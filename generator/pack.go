@@ -0,0 +1,242 @@
+package generator
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PackManifestFile describes a template pack; it sits at the root of the
+// pack directory or zip.
+const PackManifestFile = "pack.yaml"
+
+// Pack is a set of templates and generation steps installed on top of the
+// built-in ones, such as company-wide observability or billing modules.
+//
+// A pack is a directory or zip holding pack.yaml and a templates directory
+// laid out like the built-in templates. Its templates also override
+// built-in templates with the same path.
+type Pack struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description"`
+	Config      map[string]any `yaml:"config"` // defaults for Config.Extra
+	Steps       []PackStep     `yaml:"steps"`
+
+	// Source is the absolute path the pack was loaded from; it is recorded
+	// in the manifest so later runs load the pack again.
+	Source string `yaml:"-"`
+
+	templates fs.FS
+}
+
+// PackStep is a generation step contributed by a pack.
+type PackStep struct {
+	Name string `yaml:"name"`
+	// After names the step this one runs after: a built-in step such as
+	// "middleware" or another pack step as "pack/step". Empty means after
+	// every built-in step that renders project code.
+	After string `yaml:"after"`
+	// Files are output paths, rendered from the pack's templates.
+	Files []string `yaml:"files"`
+}
+
+// LoadPack loads the pack in the directory or zip file at name.
+func LoadPack(name string) (*Pack, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	var root fs.FS
+	if info.IsDir() {
+		root = os.DirFS(abs)
+	} else {
+		data, err := os.ReadFile(abs)
+		if err != nil {
+			return nil, err
+		}
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("pack %s: %w", name, err)
+		}
+		root = zr
+	}
+
+	root, err = packRoot(root)
+	if err != nil {
+		return nil, fmt.Errorf("pack %s: %w", name, err)
+	}
+	p, err := parsePack(root)
+	if err != nil {
+		return nil, fmt.Errorf("pack %s: %w", name, err)
+	}
+	p.Source = abs
+	return p, nil
+}
+
+// LoadPacks loads the packs at paths, skipping paths that name an already
+// loaded pack (the manifest records packs by absolute path).
+func LoadPacks(paths ...string) ([]*Pack, error) {
+	var packs []*Pack
+	seen := map[string]bool{}
+	for _, path := range paths {
+		p, err := LoadPack(path)
+		if err != nil {
+			return nil, err
+		}
+		if seen[p.Source] {
+			continue
+		}
+		seen[p.Source] = true
+		packs = append(packs, p)
+	}
+	return packs, nil
+}
+
+// packRoot returns the directory holding pack.yaml: the root itself, or its
+// only top-level directory, as in a zip of the pack directory.
+func packRoot(root fs.FS) (fs.FS, error) {
+	if _, err := fs.Stat(root, PackManifestFile); err == nil {
+		return root, nil
+	}
+	entries, err := fs.ReadDir(root, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		sub, err := fs.Sub(root, entries[0].Name())
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fs.Stat(sub, PackManifestFile); err == nil {
+			return sub, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s", PackManifestFile)
+}
+
+func parsePack(root fs.FS) (*Pack, error) {
+	data, err := fs.ReadFile(root, PackManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	var p Pack
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", PackManifestFile, err)
+	}
+	if p.Name == "" {
+		return nil, fmt.Errorf("%s: name is required", PackManifestFile)
+	}
+
+	seen := map[string]bool{}
+	for _, s := range p.Steps {
+		if s.Name == "" || strings.Contains(s.Name, "/") {
+			return nil, fmt.Errorf("%s: invalid step name %q", PackManifestFile, s.Name)
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("%s: duplicate step %q", PackManifestFile, s.Name)
+		}
+		seen[s.Name] = true
+		for _, f := range s.Files {
+			if !fs.ValidPath(f) || f == "." {
+				return nil, fmt.Errorf("%s: step %s: invalid file path %q", PackManifestFile, s.Name, f)
+			}
+		}
+	}
+
+	p.templates, err = fs.Sub(root, "templates")
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// WithPacks installs template packs: their steps are merged into the
+// generation steps, their templates override the built-in ones, and their
+// config defaults are added to Config.Extra.
+func WithPacks(packs ...*Pack) Option {
+	return func(g *Generator) {
+		g.packs = append(g.packs, packs...)
+	}
+}
+
+// packStepsBefore is the built-in step pack steps without an After run
+// before, so their output is in place before go.mod and the manifest.
+const packStepsBefore = "go mod"
+
+// applyPacks records the packs in the config and merges their steps into
+// steps.
+func (g *Generator) applyPacks(steps []generateStep) ([]generateStep, error) {
+	for _, p := range g.packs {
+		for k, v := range p.Config {
+			if _, ok := g.config.Extra[k]; ok {
+				continue
+			}
+			if g.config.Extra == nil {
+				g.config.Extra = map[string]any{}
+			}
+			g.config.Extra[k] = v
+		}
+		if p.Source != "" && !slices.Contains(g.config.Packs, p.Source) {
+			g.config.Packs = append(g.config.Packs, p.Source)
+		}
+
+		for _, s := range p.Steps {
+			after := s.After
+			step := generateStep{name: p.Name + "/" + s.Name, fn: g.packStep(s)}
+			i := len(steps)
+			if after == "" {
+				for j, existing := range steps {
+					if existing.name == packStepsBefore {
+						i = j
+						break
+					}
+				}
+			} else {
+				i = -1
+				for j, existing := range steps {
+					if existing.name == after {
+						i = j + 1
+						break
+					}
+				}
+				if i < 0 {
+					return nil, fmt.Errorf("pack %s: step %s runs after unknown step %q", p.Name, s.Name, after)
+				}
+			}
+			steps = append(steps[:i], append([]generateStep{step}, steps[i:]...)...)
+		}
+	}
+	return steps, nil
+}
+
+func (g *Generator) packStep(s PackStep) func() error {
+	return func() error {
+		for _, f := range s.Files {
+			if err := g.render(g.projectPath(f), g.config); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// packTemplates returns the template layers of the installed packs.
+func (g *Generator) packTemplates() []fs.FS {
+	var layers []fs.FS
+	for _, p := range g.packs {
+		layers = append(layers, p.templates)
+	}
+	return layers
+}
@@ -0,0 +1,443 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resource describes a CRUD entity scaffolded into an existing project by
+// `goth-generate resource`.
+type Resource struct {
+	Name   string // singular CamelCase name, e.g. "BlogPost"
+	Fields []Field
+}
+
+// Field is a single column of a Resource.
+type Field struct {
+	Name string // snake_case column name, e.g. "published_at"
+	Type string // one of the keys of fieldTypes
+}
+
+// fieldTypes maps the field types accepted on the command line to their
+// canonical name. The canonical name drives SQL column types, Go types and
+// form inputs.
+var fieldTypes = map[string]string{
+	"string":    "string",
+	"text":      "text",
+	"int":       "int",
+	"integer":   "int",
+	"bool":      "bool",
+	"boolean":   "bool",
+	"float":     "float",
+	"time":      "time",
+	"datetime":  "time",
+	"timestamp": "time",
+}
+
+var (
+	resourceNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+	fieldNameRe    = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+)
+
+// reservedResourcePackages are packages the base project already generates.
+var reservedResourcePackages = map[string]bool{
+	"auth": true, "database": true, "db": true, "handlers": true,
+	"middleware": true, "models": true, "session": true, "user": true,
+	"templates": true,
+}
+
+// reservedColumns are added to every resource table automatically.
+var reservedColumns = map[string]bool{"id": true, "created_at": true, "updated_at": true}
+
+// ParseResource parses a resource name and "field:type" specs as given on the
+// command line, e.g. ParseResource("Post", []string{"title:string", "body:text"}).
+func ParseResource(name string, specs []string) (*Resource, error) {
+	if !resourceNameRe.MatchString(name) {
+		return nil, fmt.Errorf("invalid resource name %q: use letters and digits, starting with a letter", name)
+	}
+	name = strings.ToUpper(name[:1]) + name[1:]
+	if reservedResourcePackages[strings.ToLower(name)] {
+		return nil, fmt.Errorf("resource name %q clashes with a generated package", name)
+	}
+	if token.IsKeyword(strings.ToLower(name)) {
+		return nil, fmt.Errorf("resource name %q is a Go keyword, which cannot name its package", name)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("resource %s needs at least one field (e.g. title:string)", name)
+	}
+
+	r := &Resource{Name: name}
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		col, typ, ok := strings.Cut(spec, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid field %q: expected name:type", spec)
+		}
+		col = toSnake(col)
+		if !fieldNameRe.MatchString(col) {
+			return nil, fmt.Errorf("invalid field name %q", col)
+		}
+		if reservedColumns[col] {
+			return nil, fmt.Errorf("field %q is added automatically", col)
+		}
+		if seen[col] {
+			return nil, fmt.Errorf("duplicate field %q", col)
+		}
+		canonical, ok := fieldTypes[strings.ToLower(typ)]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q for field %q (want string, text, int, bool, float or time)", typ, col)
+		}
+		seen[col] = true
+		r.Fields = append(r.Fields, Field{Name: col, Type: canonical})
+	}
+	return r, nil
+}
+
+// resourceData is the template data for resource scaffolding. Names are
+// derived the same way sqlc derives them from the table name, so the
+// generated service and handlers line up with sqlc's output.
+type resourceData struct {
+	Module           string
+	DBDriver         string
+	PureGoSQLite     bool   // SQLite through modernc.org/sqlite
+	SQLiteDriverName string // database/sql driver name of the SQLite driver
+	Name             string // BlogPost
+	Var              string // blogPost
+	Plural           string // BlogPosts
+	PluralVar        string // blogPosts
+	Package          string // blogpost
+	Table            string // blog_posts
+	Route            string // /blog-posts
+	Label            string // Blog post
+	Labels           string // Blog posts
+	Migration        string // 000002_create_blog_posts
+	Fields           []resourceField
+}
+
+type resourceField struct {
+	Column            string
+	GoName            string
+	Label             string
+	Type              string
+	SQLType           string
+	Placeholder       string // placeholder in CreateX
+	UpdatePlaceholder string // placeholder in UpdateX (id is the first parameter)
+}
+
+// HasType reports whether any field has one of the given canonical types.
+func (d resourceData) HasType(types ...string) bool {
+	for _, f := range d.Fields {
+		for _, t := range types {
+			if f.Type == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Columns returns the comma-separated column list used by INSERT.
+func (d resourceData) Columns() string {
+	cols := make([]string, len(d.Fields))
+	for i, f := range d.Fields {
+		cols[i] = f.Column
+	}
+	return strings.Join(cols, ", ")
+}
+
+// Placeholders returns the comma-separated VALUES placeholders used by INSERT.
+func (d resourceData) Placeholders() string {
+	ps := make([]string, len(d.Fields))
+	for i, f := range d.Fields {
+		ps[i] = f.Placeholder
+	}
+	return strings.Join(ps, ", ")
+}
+
+// SingleField reports whether the resource has one field. sqlc generates a
+// Params struct only for queries with two or more parameters, so Create then
+// takes the field's value as a plain argument.
+func (d resourceData) SingleField() bool {
+	return len(d.Fields) == 1
+}
+
+// CreateParam names the argument of Create for a single-field resource: the
+// field's name in lowerCamelCase, unless that is a keyword or clashes with
+// another identifier in Create.
+func (d resourceData) CreateParam() string {
+	goName := d.Fields[0].GoName
+	name := strings.ToLower(goName[:1]) + goName[1:]
+	switch name {
+	case "ctx", "s", "res", "id", "err", "db", "context", d.Var, d.Package:
+		return "value"
+	}
+	if token.IsKeyword(name) {
+		return "value"
+	}
+	return name
+}
+
+// IDPlaceholder is the placeholder for the id parameter of single-row queries.
+func (d resourceData) IDPlaceholder() string {
+	return placeholder(d.DBDriver, 1)
+}
+
+// Display returns a Go expression rendering the field of v as a string.
+func (f resourceField) Display(v string) string {
+	expr := v + "." + f.GoName
+	switch f.Type {
+	case "string", "text":
+		return expr
+	case "time":
+		return expr + `.Format("2006-01-02 15:04")`
+	default:
+		return "fmt.Sprint(" + expr + ")"
+	}
+}
+
+// GoType returns the Go type sqlc generates for the field's NOT NULL column.
+func (f resourceField) GoType() string {
+	switch f.Type {
+	case "int":
+		return "int64"
+	case "bool":
+		return "bool"
+	case "float":
+		return "float64"
+	case "time":
+		return "time.Time"
+	default:
+		return "string"
+	}
+}
+
+// Sample returns a Go literal of the field's type for generated tests.
+func (f resourceField) Sample() string {
+	switch f.Type {
+	case "int":
+		return "42"
+	case "bool":
+		return "true"
+	case "float":
+		return "1.5"
+	case "time":
+		return "time.Now().UTC().Truncate(time.Second)"
+	default:
+		return `"example ` + f.Label + `"`
+	}
+}
+
+func (g *Generator) newResourceData(r *Resource) (*resourceData, error) {
+	snake := toSnake(r.Name)
+	plural := pluralize(r.Name)
+	table := pluralize(snake)
+
+	version, err := g.nextMigrationVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &resourceData{
+		Module:           g.config.Module,
+		DBDriver:         g.config.DBDriver,
+		PureGoSQLite:     g.config.PureGoSQLite(),
+		SQLiteDriverName: g.config.SQLiteDriverName(),
+		Name:             r.Name,
+		Var:              strings.ToLower(r.Name[:1]) + r.Name[1:],
+		Plural:           plural,
+		PluralVar:        strings.ToLower(plural[:1]) + plural[1:],
+		Package:          strings.ToLower(r.Name),
+		Table:            table,
+		Route:            "/" + strings.ReplaceAll(table, "_", "-"),
+		Label:            toLabel(snake),
+		Labels:           toLabel(table),
+		Migration:        fmt.Sprintf("%06d_create_%s", version, table),
+	}
+	for i, f := range r.Fields {
+		d.Fields = append(d.Fields, resourceField{
+			Column:            f.Name,
+			GoName:            toCamel(f.Name),
+			Label:             toLabel(f.Name),
+			Type:              f.Type,
+			SQLType:           sqlType(g.config.DBDriver, f.Type),
+			Placeholder:       placeholder(g.config.DBDriver, i+1),
+			UpdatePlaceholder: placeholder(g.config.DBDriver, i+2),
+		})
+	}
+	return d, nil
+}
+
+// nextMigrationVersion returns the version following the highest numbered
+// migration in db/migrations, so resources continue after 000001_initial_schema.
+// Projects whose migrations are versioned by timestamp (cmd/migrate
+// -timestamp) get the current time instead.
+func (g *Generator) nextMigrationVersion() (int, error) {
+	entries, err := g.fs.ReadDir(g.projectPath("db/migrations"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+	next := 1
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		var v int
+		if _, err := fmt.Sscanf(e.Name(), "%d_", &v); err == nil && v >= next {
+			next = v + 1
+		}
+	}
+	if next > minTimestampVersion {
+		if v, _ := strconv.Atoi(time.Now().UTC().Format(migrationTimestampFormat)); v > next {
+			next = v
+		}
+	}
+	return next, nil
+}
+
+// migrationTimestampFormat is the version format of timestamped migrations;
+// versions above minTimestampVersion are taken to be timestamps.
+const (
+	migrationTimestampFormat = "20060102150405"
+	minTimestampVersion      = 1e13
+)
+
+func placeholder(driver string, n int) string {
+	switch driver {
+	case "postgres":
+		return fmt.Sprintf("$%d", n)
+	case "mysql":
+		// MySQL placeholders are positional only.
+		return "?"
+	}
+	return fmt.Sprintf("?%d", n)
+}
+
+func sqlType(driver, fieldType string) string {
+	if driver == "postgres" {
+		return map[string]string{
+			"string": "VARCHAR(255)",
+			"text":   "TEXT",
+			"int":    "BIGINT",
+			"bool":   "BOOLEAN",
+			"float":  "DOUBLE PRECISION",
+			"time":   "TIMESTAMP",
+		}[fieldType]
+	}
+	if driver == "mysql" {
+		return map[string]string{
+			"string": "VARCHAR(255)",
+			"text":   "TEXT",
+			"int":    "BIGINT",
+			"bool":   "BOOLEAN",
+			"float":  "DOUBLE",
+			"time":   "DATETIME",
+		}[fieldType]
+	}
+	return map[string]string{
+		"string": "TEXT",
+		"text":   "TEXT",
+		"int":    "INTEGER",
+		"bool":   "BOOLEAN",
+		"float":  "REAL",
+		"time":   "DATETIME",
+	}[fieldType]
+}
+
+// toSnake converts CamelCase to snake_case ("BlogPost" -> "blog_post").
+func toSnake(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 && s[i-1] != '_' {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// toCamel converts snake_case to the Go field name sqlc would generate
+// ("published_at" -> "PublishedAt", "author_id" -> "AuthorID").
+func toCamel(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		if part == "" {
+			continue
+		}
+		if part == "id" {
+			b.WriteString("ID")
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// toLabel converts snake_case to a human label ("published_at" -> "Published at").
+func toLabel(s string) string {
+	s = strings.ReplaceAll(s, "_", " ")
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// pluralize applies the regular English plural rules, which is what sqlc
+// expects when it singularizes table names back into struct names.
+func pluralize(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	default:
+		return s + "s"
+	}
+}
+
+// GenerateResource scaffolds a CRUD resource into the existing project at
+// Config.OutputDir: a migration pair, sqlc queries, a service package,
+// httprouter handlers, templ views and tests.
+func (g *Generator) GenerateResource(r *Resource) error {
+	d, err := g.newResourceData(r)
+	if err != nil {
+		return fmt.Errorf("failed to inspect migrations: %w", err)
+	}
+	if _, err := g.fs.ReadDir(g.projectPath("internal", d.Package)); err == nil {
+		return fmt.Errorf("internal/%s already exists", d.Package)
+	}
+
+	files := []struct {
+		path     string
+		template string
+	}{
+		{"db/migrations/" + d.Migration + ".up.sql", "resource/migration.up.sql"},
+		{"db/migrations/" + d.Migration + ".down.sql", "resource/migration.down.sql"},
+		{"db/queries/" + d.Table + ".sql", "resource/queries.sql"},
+		{"internal/" + d.Package + "/" + d.Package + ".go", "resource/service.go"},
+		{"internal/handlers/" + d.Table + ".go", "resource/handlers.go"},
+		{"internal/handlers/" + d.Table + "_test.go", "resource/handlers_test.go"},
+		{"web/templates/" + d.Table + ".templ", "resource/views.templ"},
+	}
+	if g.config.DBDriver == "sqlite" {
+		files = append(files, struct {
+			path     string
+			template string
+		}{"internal/" + d.Package + "/" + d.Package + "_test.go", "resource/service_test.go"})
+	}
+
+	g.step = "resource " + r.Name
+	for _, f := range files {
+		if err := g.writeTemplate(g.projectPath(f.path), f.template, d); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", f.path, err)
+		}
+	}
+	return g.apply()
+}
@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerateResourceSingleField(t *testing.T) {
+	for _, db := range checkDatabases() {
+		t.Run(strings.TrimSpace(db[0]+" "+db[1]), func(t *testing.T) {
+			config := Config{
+				Name:         "app",
+				Module:       "example.com/app",
+				Port:         "8080",
+				DBDriver:     db[0],
+				SQLiteDriver: db[1],
+			}
+			fsys := generateMem(t, config)
+
+			r, err := ParseResource("Category", []string{"title:string"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			g := New(&config, WithFS(fsys))
+			d, err := g.newResourceData(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := g.GenerateResource(r); err != nil {
+				t.Fatalf("GenerateResource: %v", err)
+			}
+
+			service, err := fsys.ReadFile("internal/category/category.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []byte("Create(ctx context.Context, title string)"); !bytes.Contains(service, want) {
+				t.Errorf("service does not contain %q:\n%s", want, service)
+			}
+			if err := typeCheck(&config, memFiles(t, fsys), d); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestParseResourceRejectsKeywords(t *testing.T) {
+	for _, name := range []string{"Type", "Select", "Map", "func"} {
+		if _, err := ParseResource(name, []string{"title:string"}); err == nil {
+			t.Errorf("ParseResource(%q): want an error", name)
+		}
+	}
+	if _, err := ParseResource("Mapping", []string{"title:string"}); err != nil {
+		t.Errorf("ParseResource(Mapping): %v", err)
+	}
+}
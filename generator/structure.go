@@ -1,9 +1,5 @@
 package generator
 
-import (
-	"os"
-)
-
 func (g *Generator) generateStructure() error {
 	dirs := []string{
 		"cmd/server",
@@ -19,12 +15,11 @@ func (g *Generator) generateStructure() error {
 		"web/static/js",
 		"cmd/migrate",
 		"db/migrations",
-		"db/schema",
 		"db/queries",
 	}
 
 	for _, dir := range dirs {
-		if err := os.MkdirAll(g.projectPath(dir), 0755); err != nil {
+		if err := g.mkdir(g.projectPath(dir)); err != nil {
 			return err
 		}
 	}
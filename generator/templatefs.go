@@ -0,0 +1,161 @@
+package generator
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// builtinTemplates holds the files every project is generated from, laid out
+// by output path. A file ending in .tmpl is executed with text/template and
+// written without the suffix; any other file is copied as is. Dotfiles are
+// stored without the leading dot so they do not affect this repository
+// (templates/gitignore becomes .gitignore), and Go files always carry the
+// .tmpl suffix so the go tool does not see them as packages of this module.
+// Resource templates live under templates/resource.
+//
+//go:embed templates
+var builtinTemplates embed.FS
+
+// BuiltinTemplates returns the templates shipped with the generator, in the
+// layout override directories use.
+func BuiltinTemplates() fs.FS {
+	sub, err := fs.Sub(builtinTemplates, "templates")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// WithTemplates layers template overrides over the built-in templates. Any
+// file in fsys with the same relative path as a built-in template replaces
+// it; earlier layers win over later ones.
+func WithTemplates(layers ...fs.FS) Option {
+	return func(g *Generator) {
+		g.templates = append(g.templates, layers...)
+	}
+}
+
+// UserTemplateDir is the per-user override directory,
+// $XDG_CONFIG_HOME/goth-generate/templates or ~/.config/goth-generate/templates.
+// It returns "" if no home directory can be determined.
+func UserTemplateDir() string {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "goth-generate", "templates")
+}
+
+// TemplateLayers returns the override layers for dirs followed by the user
+// template directory, if it exists. Empty dirs are ignored; any other dir
+// that does not exist is an error.
+func TemplateLayers(dirs ...string) ([]fs.FS, error) {
+	var layers []fs.FS
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if info, err := os.Stat(dir); err != nil {
+			return nil, err
+		} else if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", dir)
+		}
+		layers = append(layers, os.DirFS(dir))
+	}
+	if dir := UserTemplateDir(); dir != "" {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			layers = append(layers, os.DirFS(dir))
+		}
+	}
+	return layers, nil
+}
+
+// templateCandidates returns the names a template may be stored under, most
+// specific first.
+func templateCandidates(name string) []string {
+	names := []string{name + ".tmpl", name}
+	if dir, file := path.Split(name); strings.HasPrefix(file, ".") {
+		undotted := dir + strings.TrimPrefix(file, ".")
+		names = append(names, undotted+".tmpl", undotted)
+	}
+	return names
+}
+
+// lookupTemplate finds the template for name in the override layers, then the
+// packs, then the built-in templates. It reports whether the content must be
+// executed.
+func (g *Generator) lookupTemplate(name string) ([]byte, bool, error) {
+	layers := append(append([]fs.FS{}, g.templates...), g.packTemplates()...)
+	layers = append(layers, BuiltinTemplates())
+	for _, layer := range layers {
+		for _, candidate := range templateCandidates(name) {
+			data, err := fs.ReadFile(layer, candidate)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, false, err
+			}
+			return data, strings.HasSuffix(candidate, ".tmpl"), nil
+		}
+	}
+	return nil, false, fmt.Errorf("no template for %s", name)
+}
+
+// overridden reports whether any of the named templates comes from an
+// override layer or a pack instead of the built-in templates.
+func (g *Generator) overridden(names ...string) (bool, error) {
+	layers := append(append([]fs.FS{}, g.templates...), g.packTemplates()...)
+	for _, name := range names {
+		for _, layer := range layers {
+			for _, candidate := range templateCandidates(name) {
+				_, err := fs.Stat(layer, candidate)
+				if err == nil {
+					return true, nil
+				}
+				if !errors.Is(err, fs.ErrNotExist) {
+					return false, err
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// render writes the file at path from the template of the same name.
+func (g *Generator) render(path string, data interface{}) error {
+	return g.writeTemplate(path, path, data)
+}
+
+// writeTemplate writes the file at path from the named template.
+func (g *Generator) writeTemplate(path string, name string, data interface{}) error {
+	content, execute, err := g.lookupTemplate(name)
+	if err != nil {
+		return err
+	}
+	if !execute {
+		return g.writeFile(path, string(content))
+	}
+
+	t, err := template.New(name).Parse(string(content))
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return err
+	}
+	return g.writeFile(path, buf.String())
+}
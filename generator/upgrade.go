@@ -0,0 +1,186 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// UpgradeStatus records what Upgrade or Add did with a generated file.
+type UpgradeStatus string
+
+const (
+	UpgradeUnchanged UpgradeStatus = "unchanged" // the project already matches the new output
+	UpgradeAdded     UpgradeStatus = "added"     // new in this generator version
+	UpgradeUpdated   UpgradeStatus = "updated"   // untouched in the project; replaced with the new output
+	UpgradeKept      UpgradeStatus = "kept"      // generator output did not change; project version kept
+	UpgradeMerged    UpgradeStatus = "merged"    // project and generator changes merged cleanly
+	UpgradeConflict  UpgradeStatus = "conflict"  // merged with conflict markers
+	UpgradeSkipped   UpgradeStatus = "skipped"   // left alone; see UpgradeFile.Note
+	UpgradeEdited    UpgradeStatus = "edited"    // changed in place: main.go by Add, go.mod and go.sum
+)
+
+// UpgradeFile is the outcome of Upgrade or Add for one file.
+type UpgradeFile struct {
+	Path      string
+	Status    UpgradeStatus
+	Conflicts int    // conflicting regions, for UpgradeConflict
+	Note      string // why the file was skipped
+}
+
+// Pristine returns what the current generator produces for config, with the
+// template overrides and packs it records, keyed by project-relative path,
+// without touching disk.
+func Pristine(config Config) (map[string][]byte, error) {
+	files, err := pristineFiles(config)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][]byte, len(files))
+	for _, f := range files {
+		out[f.Path] = f.Content
+	}
+	return out, nil
+}
+
+func pristineFiles(config Config) ([]PlannedFile, error) {
+	opts, err := projectOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	gen := New(&config, append(opts, WithFS(NewMemFS()))...)
+	// Existing projects are reproduced as recorded, even if their
+	// configuration would no longer pass Validate, and without running
+	// their hooks.
+	gen.reproducing = true
+	if err := gen.Generate(); err != nil {
+		return nil, err
+	}
+	return gen.Files(), nil
+}
+
+// projectOptions returns the template overrides and packs recorded in
+// config, so the output is rendered the way `goth-generate new` rendered it.
+func projectOptions(config Config) ([]Option, error) {
+	layers, err := TemplateLayers(config.Templates)
+	if err != nil {
+		return nil, err
+	}
+	packs, err := LoadPacks(config.Packs...)
+	if err != nil {
+		return nil, err
+	}
+	return []Option{WithTemplates(layers...), WithPacks(packs...)}, nil
+}
+
+// Upgrade brings the project in fsys, described by its manifest, up to the
+// output of the current generator. base is the pristine output of the
+// generator version recorded in the manifest. Each file is three-way merged:
+// changes made in the project are kept, changes made by the generator are
+// applied, and regions changed by both are written with conflict markers.
+// Files the ledger shows as untouched are replaced outright; go.mod and
+// go.sum only gain the pinned dependencies of the current version. The
+// manifest is updated to the current Version and the ledger to the new
+// output. With dryRun nothing is written.
+func Upgrade(fsys FS, m *Manifest, base map[string][]byte, dryRun bool) ([]UpgradeFile, error) {
+	newFiles, err := pristineFiles(m.Config)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := ReadLedger(fsys)
+	if err != nil {
+		return nil, err
+	}
+	labels := MergeLabels{
+		Ours:   "project",
+		Base:   "goth-generate " + m.GeneratorVersion,
+		Theirs: "goth-generate " + Version,
+	}
+
+	var results []UpgradeFile
+	for _, f := range newFiles {
+		// The manifest and the ledger are rewritten below.
+		if f.Path == ManifestFile || f.Path == LedgerFile {
+			continue
+		}
+
+		var (
+			res   UpgradeFile
+			write []byte
+		)
+		if f.Path == "go.mod" || f.Path == "go.sum" {
+			// The project owns them once created; they only gain the
+			// pinned dependencies of the new version.
+			res, write, err = mergeModFile(fsys, f, ledger, &m.Config)
+		} else {
+			res, write, err = mergeFile(fsys, f, base, ledger, labels)
+			if err == nil && res.Status != UpgradeSkipped {
+				ledger[f.Path] = Sum(f.Content)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+
+		if write != nil && !dryRun {
+			if err := fsys.WriteFile(f.Path, write); err != nil {
+				return nil, fmt.Errorf("failed to write %s: %w", f.Path, err)
+			}
+		}
+	}
+
+	if !dryRun {
+		upgraded := *m
+		upgraded.GeneratorVersion = Version
+		data, err := upgraded.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		if err := fsys.WriteFile(ManifestFile, data); err != nil {
+			return nil, err
+		}
+		ledger[ManifestFile] = Sum(data)
+		if err := fsys.WriteFile(LedgerFile, ledger.Marshal()); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// mergeFile three-way merges the newly generated f into the project in fsys,
+// with base holding the output f would have had when the project was last
+// generated. It returns the outcome and the content to write, if any.
+func mergeFile(fsys FS, f PlannedFile, base map[string][]byte, ledger Ledger, labels MergeLabels) (UpgradeFile, []byte, error) {
+	res := UpgradeFile{Path: f.Path}
+	baseContent, inBase := base[f.Path]
+	ours, err := fsys.ReadFile(f.Path)
+	var write []byte
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && inBase:
+		res.Status, res.Note = UpgradeSkipped, "deleted in the project"
+	case errors.Is(err, fs.ErrNotExist):
+		res.Status, write = UpgradeAdded, f.Content
+	case err != nil:
+		return res, nil, err
+	case bytes.Equal(ours, f.Content):
+		res.Status = UpgradeUnchanged
+	case hasConflictMarkers(ours):
+		res.Status, res.Note = UpgradeSkipped, "has unresolved conflict markers"
+	case (inBase || ledger[f.Path] != "") && strings.HasPrefix(f.Path, "db/migrations/"):
+		res.Status, res.Note = UpgradeSkipped, "applied migrations are never rewritten"
+	case inBase && bytes.Equal(ours, baseContent), ledger.Untouched(f.Path, ours):
+		res.Status, write = UpgradeUpdated, f.Content
+	case inBase && bytes.Equal(baseContent, f.Content):
+		res.Status = UpgradeKept
+	default:
+		merged, conflicts := Merge3(baseContent, ours, f.Content, labels)
+		res.Status, res.Conflicts, write = UpgradeMerged, conflicts, merged
+		if conflicts > 0 {
+			res.Status = UpgradeConflict
+		}
+	}
+	return res, write, nil
+}
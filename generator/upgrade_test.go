@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const overriddenMiddleware = "internal/middleware/middleware.go"
+
+// overrideMiddleware returns a template override directory that replaces
+// internal/middleware/middleware.go with the built-in template plus a
+// comment, and the comment.
+func overrideMiddleware(t *testing.T) (string, []byte) {
+	t.Helper()
+	builtin, err := fs.ReadFile(BuiltinTemplates(), overriddenMiddleware+".tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	marker := []byte("// Company middleware.\n")
+	dir := t.TempDir()
+	name := filepath.Join(dir, filepath.FromSlash(overriddenMiddleware+".tmpl"))
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, append(append([]byte(nil), marker...), builtin...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir, marker
+}
+
+// generateMem generates the project for config into a new MemFS with the
+// template overrides and packs config records, the way `goth-generate new`
+// does.
+func generateMem(t *testing.T, config Config) *MemFS {
+	t.Helper()
+	// Keep the user's own overrides out of the output.
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	opts, err := projectOptions(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := NewMemFS()
+	if err := New(&config, append(opts, WithFS(fsys))...).Generate(); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	return fsys
+}
+
+// memFiles returns every file in fsys, keyed by path.
+func memFiles(t *testing.T, fsys *MemFS) map[string][]byte {
+	t.Helper()
+	files := map[string][]byte{}
+	for _, p := range fsys.Paths() {
+		data, err := fsys.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[p] = data
+	}
+	return files
+}
+
+// assertUntouched fails unless Status reports path as untouched.
+func assertUntouched(t *testing.T, fsys FS, path string) {
+	t.Helper()
+	statuses, err := Status(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range statuses {
+		if st.Path == path {
+			if st.State != StateUntouched {
+				t.Errorf("status of %s = %s, want %s", path, st.State, StateUntouched)
+			}
+			return
+		}
+	}
+	t.Errorf("%s is not in the ledger", path)
+}
+
+func TestUpgradeKeepsTemplateOverrides(t *testing.T) {
+	dir, marker := overrideMiddleware(t)
+	config := Config{
+		Name:      "app",
+		Module:    "example.com/app",
+		Port:      "8080",
+		DBDriver:  "sqlite",
+		Templates: dir,
+	}
+	fsys := generateMem(t, config)
+	base, err := Pristine(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manifest{Config: config}
+	m.GeneratorVersion = "1.1.0"
+	results, err := Upgrade(fsys, m, base, false)
+	if err != nil {
+		t.Fatalf("Upgrade: %v", err)
+	}
+	for _, r := range results {
+		if r.Path == overriddenMiddleware && r.Status != UpgradeUnchanged {
+			t.Errorf("%s: %s, want %s", r.Path, r.Status, UpgradeUnchanged)
+		}
+	}
+
+	got, err := fsys.ReadFile(overriddenMiddleware)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(got, marker) {
+		t.Errorf("%s lost the template override:\n%s", overriddenMiddleware, got)
+	}
+	assertUntouched(t, fsys, overriddenMiddleware)
+}
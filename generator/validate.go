@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// DBDrivers are the database drivers projects can be generated for.
+var DBDrivers = []string{"postgres", "sqlite", "mysql"}
+
+// SQLiteDrivers are the SQLite database/sql drivers: mattn/go-sqlite3, which
+// needs CGO, and the pure Go modernc.org/sqlite.
+var SQLiteDrivers = []string{"mattn", "modernc"}
+
+// maxNameLength keeps the project name usable as a PostgreSQL database name,
+// the tightest of the places it ends up in.
+const maxNameLength = 63
+
+// projectNameRe matches names that are valid npm package names, database
+// names and Docker image names without quoting.
+var projectNameRe = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// FieldError is a problem with one Config field.
+type FieldError struct {
+	Field  string `json:"field"` // the flag setting the field: "name", "module", "db", "sqlite-driver", "port" or "hook"
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// ValidationError is returned by Config.Validate and Generate. It lists every
+// invalid field, not just the first.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap lets errors.As find the individual *FieldError values.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Field returns the error for the named field, or nil if it is valid.
+func (e *ValidationError) Field(name string) *FieldError {
+	for _, err := range e.Errors {
+		if err.Field == name {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that the configuration generates a working project: a
+// name usable as an npm package and database name, a module path Go
+// accepts, a supported database (and SQLite) driver, a TCP port and complete
+// hook commands. It returns a *ValidationError listing every invalid field.
+func (c *Config) Validate() error {
+	var errs []*FieldError
+	for _, err := range []*FieldError{
+		ValidateName(c.Name),
+		ValidateModule(c.Module),
+		ValidateDBDriver(c.DBDriver),
+		ValidateSQLiteDriver(c.DBDriver, c.SQLiteDriver),
+		ValidatePort(c.Port),
+	} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, h := range c.Hooks {
+		if err := ValidateHook(h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// ValidateName checks a project name. It ends up in the database name,
+// package.json and Docker image tags, so it is restricted to lowercase
+// letters, digits, '-' and '_'.
+func ValidateName(name string) *FieldError {
+	switch {
+	case name == "":
+		return &FieldError{"name", name, "a project name is required"}
+	case len(name) > maxNameLength:
+		return &FieldError{"name", name, fmt.Sprintf("use at most %d characters", maxNameLength)}
+	case !projectNameRe.MatchString(name):
+		return &FieldError{"name", name, "use lowercase letters, digits, '-' and '_', starting with a letter"}
+	}
+	return nil
+}
+
+// ValidateModule checks a Go module path with the rules of the go command.
+func ValidateModule(path string) *FieldError {
+	if path == "" {
+		return &FieldError{"module", path, "a module path is required"}
+	}
+	if strings.Contains(path, "://") {
+		return &FieldError{"module", path, "leave out the URL scheme, e.g. github.com/user/project"}
+	}
+	if err := module.CheckImportPath(path); err != nil {
+		reason := err.Error()
+		// The error repeats the path: `malformed import path "x": reason`.
+		if _, after, ok := strings.Cut(reason, strconv.Quote(path)+": "); ok {
+			reason = after
+		}
+		return &FieldError{"module", path, reason}
+	}
+	return nil
+}
+
+// ValidateDBDriver checks that driver is one of DBDrivers.
+func ValidateDBDriver(driver string) *FieldError {
+	for _, d := range DBDrivers {
+		if driver == d {
+			return nil
+		}
+	}
+	last := len(DBDrivers) - 1
+	return &FieldError{"db", driver, "choose " + strings.Join(DBDrivers[:last], ", ") + " or " + DBDrivers[last]}
+}
+
+// ValidateSQLiteDriver checks that sqliteDriver is empty or one of
+// SQLiteDrivers, and is only set for the sqlite database driver.
+func ValidateSQLiteDriver(dbDriver, sqliteDriver string) *FieldError {
+	if sqliteDriver == "" {
+		return nil
+	}
+	if dbDriver != "sqlite" {
+		return &FieldError{"sqlite-driver", sqliteDriver, "only applies to the sqlite database driver"}
+	}
+	for _, d := range SQLiteDrivers {
+		if sqliteDriver == d {
+			return nil
+		}
+	}
+	return &FieldError{"sqlite-driver", sqliteDriver, "choose " + strings.Join(SQLiteDrivers, " or ")}
+}
+
+// ValidatePort checks that port is a TCP port number.
+func ValidatePort(port string) *FieldError {
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return &FieldError{"port", port, "use a port number between 1 and 65535"}
+	}
+	return nil
+}
+
+// ValidateHook checks that a hook command names one step and a command.
+// Whether the step exists is only known once packs are installed, so
+// Generate checks that.
+func ValidateHook(h HookCommand) *FieldError {
+	switch {
+	case (h.Before == "") == (h.After == ""):
+		return &FieldError{"hook", h.Run, "set exactly one of before and after"}
+	case h.Run == "":
+		return &FieldError{"hook", h.Run, "a command to run is required"}
+	}
+	return nil
+}
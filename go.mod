@@ -1,3 +1,11 @@
 module github.com/bennett-matt/goth-generator
 
 go 1.23.4
+
+require (
+	golang.org/x/mod v0.27.0
+	golang.org/x/term v0.34.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.35.0 // indirect
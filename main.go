@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -11,61 +12,132 @@ import (
 	"github.com/bennett-matt/goth-generator/generator"
 )
 
+// Exit codes shared by every command.
+const (
+	exitOK    = 0
+	exitError = 1 // the command ran and failed
+	exitUsage = 2 // bad flags or arguments
+)
+
+// command is a goth-generate subcommand.
+type command struct {
+	name    string
+	args    string // argument synopsis shown in usage
+	summary string
+	run     func(args []string) int
+}
+
+// commands is populated in init because each command's flag set looks up its
+// own usage text here.
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"new", "[flags]", "Generate a new project", runNew},
+		{"add", "[flags] <module>", "Add a feature (auth, sessions, users) to an existing project", runAdd},
+		{"resource", "[flags] Name field:type...", "Scaffold a CRUD resource in an existing project", runResource},
+		{"doctor", "[flags]", "Check toolchain prerequisites", runDoctor},
+		{"upgrade", "[flags]", "Merge newer generator output into an existing project", runUpgrade},
+		{"status", "[flags]", "Report generated files that were modified or deleted", runStatus},
+		{"version", "", "Print version and exit", runVersion},
+	}
+}
+
 func main() {
-	var (
-		version     = flag.Bool("version", false, "Print version and exit")
-		name        = flag.String("name", "", "Project name (required)")
-		module      = flag.String("module", "", "Go module path (e.g., github.com/user/project)")
-		output      = flag.String("output", ".", "Output directory for generated project")
-		dbDriver    = flag.String("db", "postgres", "Database driver (postgres or sqlite)")
-		port        = flag.String("port", "8080", "Server port")
-		withAuth    = flag.Bool("auth", true, "Include authentication")
-		withUsers   = flag.Bool("users", true, "Include user management")
-		withSessions = flag.Bool("sessions", true, "Include session management")
-	)
-	flag.Parse()
-
-	if *version {
-		fmt.Println("goth-generate 1.1.0")
-		return
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		// On a terminal, a bare goth-generate starts the new project wizard.
+		if isTerminal(os.Stdin) {
+			return runNew(nil)
+		}
+		usage()
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			if cmd := lookup(args[1]); cmd != nil {
+				return cmd.run([]string{"-h"})
+			}
+		}
+		usage()
+		return exitOK
+	case "-version", "--version":
+		return runVersion(nil)
+	}
+
+	// Flags without a command keep working as `goth-generate new`.
+	if strings.HasPrefix(args[0], "-") {
+		return runNew(args)
 	}
 
-	if *name == "" {
-		fmt.Fprintf(os.Stderr, "Error: -name is required\n")
-		flag.Usage()
-		os.Exit(1)
+	cmd := lookup(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n\n", args[0])
+		usage()
+		return exitUsage
 	}
+	return cmd.run(args[1:])
+}
 
-	if *module == "" {
-		*module = strings.ToLower(*name)
+func lookup(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
 	}
-	*module = normalizeModulePath(*module)
-
-	config := &generator.Config{
-		Name:         *name,
-		Module:       *module,
-		OutputDir:    *output,
-		DBDriver:     *dbDriver,
-		Port:         *port,
-		WithAuth:     *withAuth,
-		WithUsers:    *withUsers,
-		WithSessions: *withSessions,
-		GoVersion:    goVersionMinor(),
+	return nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: goth-generate <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
 	}
+	fmt.Fprintf(os.Stderr, "\nRun 'goth-generate <command> -h' for command flags.\n")
+	fmt.Fprintf(os.Stderr, "Flags without a command (goth-generate -name myapp) run 'new'.\n")
+}
 
-	gen := generator.New(config)
-	if err := gen.Generate(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error generating project: %v\n", err)
-		os.Exit(1)
+// newFlagSet returns a flag set for the named command whose usage prints the
+// command synopsis and summary before the flag defaults.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		cmd := lookup(name)
+		fmt.Fprintf(os.Stderr, "Usage: goth-generate %s %s\n\n%s\n", cmd.name, cmd.args, cmd.summary)
+		var hasFlags bool
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintf(os.Stderr, "\nFlags:\n")
+			fs.PrintDefaults()
+		}
 	}
+	return fs
+}
 
-	fmt.Printf("✅ Successfully generated project '%s' in %s\n", *name, *output)
-	fmt.Printf("📦 Module: %s\n", *module)
-	fmt.Printf("🚀 Next steps:\n")
-	fmt.Printf("   1. cd %s\n", *output)
-	fmt.Printf("   2. make setup\n")
-	fmt.Printf("   3. start db (docker-compose up -d db)\n")
-	fmt.Printf("   4. make dev\n")
+// parseFlags parses args into fs. When the command should stop (after -h or
+// a bad flag) it returns false along with the exit code to use.
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+func runVersion(args []string) int {
+	fs := newFlagSet("version")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	fmt.Printf("goth-generate %s\n", generator.Version)
+	return exitOK
 }
 
 // normalizeModulePath strips URL schemes (https://, http://) from module paths.
@@ -80,16 +152,18 @@ func normalizeModulePath(module string) string {
 }
 
 // goVersionMinor returns the current Go version as major.minor (e.g. "1.24")
-// for use in generated Dockerfiles and docs. Falls back to "1.23" if detection fails.
+// for use in generated Dockerfiles and docs. Falls back to
+// generator.MinGoVersion, with a warning on stderr, if detection fails.
 func goVersionMinor() string {
 	out, err := exec.Command("go", "version").Output()
-	if err != nil {
-		return "1.23"
-	}
-	// "go version go1.24.3 linux/amd64" -> "1.24"
-	re := regexp.MustCompile(`go(\d+\.\d+)`)
-	if m := re.FindStringSubmatch(string(out)); len(m) >= 2 {
-		return m[1]
+	if err == nil {
+		// "go version go1.24.3 linux/amd64" -> "1.24"
+		re := regexp.MustCompile(`go(\d+\.\d+)`)
+		if m := re.FindStringSubmatch(string(out)); len(m) >= 2 {
+			return m[1]
+		}
+		err = fmt.Errorf("unrecognized output %q", strings.TrimSpace(string(out)))
 	}
-	return "1.23"
+	fmt.Fprintf(os.Stderr, "Warning: could not detect the Go version (%v); assuming Go %s\n", err, generator.MinGoVersion)
+	return generator.MinGoVersion
 }
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bennett-matt/goth-generator/generator"
+)
+
+// runNew implements `goth-generate new`, which is also what runs when the
+// binary is invoked with flags only.
+func runNew(args []string) int {
+	fs := newFlagSet("new")
+	var (
+		showVersion  = fs.Bool("version", false, "Print version and exit")
+		name         = fs.String("name", "", "Project name (required)")
+		module       = fs.String("module", "", "Go module path (e.g., github.com/user/project)")
+		output       = fs.String("output", ".", "Output directory for generated project")
+		dbDriver     = fs.String("db", "postgres", "Database driver (postgres, sqlite or mysql)")
+		sqliteDriver = fs.String("sqlite-driver", "", "With -db sqlite, the Go driver: mattn (default; needs CGO) or modernc (pure Go, static builds)")
+		port         = fs.String("port", "8080", "Server port")
+		withAuth     = fs.Bool("auth", true, "Include authentication")
+		withUsers    = fs.Bool("users", true, "Include user management")
+		withSessions = fs.Bool("sessions", true, "Include session management")
+		dryRun       = fs.Bool("dry-run", false, "Print the files that would be generated without writing anything")
+		showContent  = fs.Bool("show-content", false, "With -dry-run, also print the content of every file")
+		conflict     = fs.String("conflict", "refuse", "What to do with existing files that differ from the generated output: refuse, skip, force, backup (keep a .orig copy) or diff")
+		archive      = fs.String("archive", "", "Write the project to a .tar.gz or .zip archive instead of a directory (\"-\" for tar.gz on stdout)")
+		outputFormat = fs.String("output-format", formatText, "Output format: text, or json for a machine-readable report on stdout")
+		templates    = fs.String("templates", "", "Directory of template overrides, laid out like the generated project (also read from "+generator.UserTemplateDir()+")")
+		packs        []string
+		settings     = map[string]any{}
+		hooks        []generator.HookCommand
+	)
+	fs.Func("pack", "Install a template pack (directory or .zip); repeatable", func(v string) error {
+		packs = append(packs, v)
+		return nil
+	})
+	fs.Func("set", "Set a pack setting as key=value, available to templates as .Extra.key; repeatable", func(v string) error {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("want key=value")
+		}
+		settings[key] = value
+		return nil
+	})
+	fs.Func("hook", "Run a shell command around a generation step, as before:STEP=COMMAND or after:STEP=COMMAND (after:write runs in the finished project); recorded in "+generator.ManifestFile+", repeatable", func(v string) error {
+		h, err := parseHook(v)
+		if err != nil {
+			return err
+		}
+		hooks = append(hooks, h)
+		return nil
+	})
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if *showVersion {
+		return runVersion(nil)
+	}
+	if !validOutputFormat(*outputFormat) {
+		fmt.Fprintf(os.Stderr, "Error: unknown -output-format %q (want text or json)\n", *outputFormat)
+		return exitUsage
+	}
+	jsonOutput := *outputFormat == formatJSON
+	// fail reports err in the chosen output format and returns code.
+	fail := func(code int, err error) int {
+		if jsonOutput {
+			printJSON(errorReport(err))
+		} else {
+			printError(err)
+		}
+		return code
+	}
+
+	config := &generator.Config{
+		Name:         *name,
+		Module:       *module,
+		OutputDir:    *output,
+		DBDriver:     *dbDriver,
+		SQLiteDriver: *sqliteDriver,
+		Port:         *port,
+		WithAuth:     *withAuth,
+		WithUsers:    *withUsers,
+		WithSessions: *withSessions,
+		GoVersion:    goVersionMinor(),
+	}
+
+	// Re-running into an existing project starts from its manifest, so only
+	// the flags given on the command line change the recorded configuration.
+	if m, err := generator.LoadManifest(*output); err == nil {
+		config = &m.Config
+		overrideConfig(config, fs)
+		if !jsonOutput {
+			fmt.Printf("📄 Using %s from %s\n", generator.ManifestFile, *output)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fail(exitError, err)
+	}
+
+	// Without a name, ask for the configuration on a terminal; scripts and
+	// CI, whose stdin is not a terminal, keep getting a usage error.
+	if config.Name == "" && !jsonOutput && isTerminal(os.Stdin) {
+		if err := runWizard(config, fs, os.Stdin, os.Stderr); err != nil {
+			return fail(exitError, err)
+		}
+	}
+	if config.Name == "" && jsonOutput {
+		return fail(exitUsage, errors.New("-name is required"))
+	}
+	if config.Name == "" {
+		fmt.Fprintf(os.Stderr, "Error: -name is required\n")
+		fs.Usage()
+		return exitUsage
+	}
+
+	if config.Module == "" {
+		config.Module = strings.ToLower(config.Name)
+	}
+	config.Module = normalizeModulePath(config.Module)
+	for _, h := range hooks {
+		// A re-run with the same -hook must not record it twice.
+		if !slices.Contains(config.Hooks, h) {
+			config.Hooks = append(config.Hooks, h)
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return fail(exitUsage, err)
+	}
+
+	for k, v := range settings {
+		if config.Extra == nil {
+			config.Extra = map[string]any{}
+		}
+		config.Extra[k] = v
+	}
+
+	// The override directory is recorded like packs, so re-running into the
+	// project renders the same files without repeating -templates.
+	if *templates != "" {
+		dir, err := filepath.Abs(*templates)
+		if err != nil {
+			return fail(exitError, err)
+		}
+		config.Templates = dir
+	}
+	layers, err := generator.TemplateLayers(config.Templates)
+	if err != nil {
+		return fail(exitError, err)
+	}
+	installed, err := generator.LoadPacks(append(config.Packs, packs...)...)
+	if err != nil {
+		return fail(exitError, err)
+	}
+
+	if *archive != "" {
+		if *dryRun {
+			return fail(exitUsage, errors.New("-dry-run lists the files without writing them; leave out -archive"))
+		}
+		if *archive == "-" && jsonOutput {
+			return fail(exitUsage, errors.New("-archive - writes the archive to stdout; name a file to use -output-format json"))
+		}
+		format := generator.ArchiveTarGz
+		if *archive != "-" {
+			if format, err = generator.ArchiveFormatFor(*archive); err != nil {
+				return fail(exitUsage, err)
+			}
+		}
+		return writeArchive(config, *archive, format, jsonOutput, generator.WithTemplates(layers...), generator.WithPacks(installed...))
+	}
+
+	strategy, err := generator.ParseConflictStrategy(*conflict)
+	if err != nil {
+		return fail(exitUsage, err)
+	}
+
+	opts := []generator.Option{
+		generator.WithConflictStrategy(strategy),
+		generator.WithTemplates(layers...),
+		generator.WithPacks(installed...),
+	}
+	if *dryRun {
+		opts = append(opts, generator.WithDryRun())
+	}
+
+	gen := generator.New(config, opts...)
+	if err := gen.Generate(); err != nil {
+		var stepErr *generator.UnknownStepError
+		if errors.As(err, &stepErr) {
+			return fail(exitUsage, err)
+		}
+		if jsonOutput {
+			return fail(exitError, err)
+		}
+		fmt.Fprintf(os.Stderr, "Error generating project: %v\n", err)
+		var conflictErr *generator.ConflictError
+		if errors.As(err, &conflictErr) {
+			fmt.Fprintf(os.Stderr, "Nothing was written. Re-run with -conflict skip, force, backup or diff, or -dry-run to review.\n")
+		}
+		return exitError
+	}
+
+	if jsonOutput {
+		printJSON(generationReport(gen, config, *output, *dryRun))
+		return exitOK
+	}
+
+	if *dryRun {
+		printPlan(gen, *output, *showContent)
+		return exitOK
+	}
+
+	printConflicts(gen)
+	printWarnings(gen)
+	fmt.Printf("✅ Successfully generated project '%s' in %s\n", config.Name, *output)
+	fmt.Printf("📦 Module: %s\n", config.Module)
+	fmt.Printf("🚀 Next steps:\n")
+	for i, step := range nextSteps(config, *output) {
+		fmt.Printf("   %d. %s\n", i+1, step)
+	}
+	return exitOK
+}
+
+// printError prints err, with each invalid field of a
+// *generator.ValidationError on its own line.
+func printError(err error) {
+	var verr *generator.ValidationError
+	if !errors.As(err, &verr) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	for _, ferr := range verr.Errors {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", ferr)
+	}
+}
+
+// parseHook parses a -hook value such as "after:write=git init".
+func parseHook(v string) (generator.HookCommand, error) {
+	spec, command, ok := strings.Cut(v, "=")
+	phase, step, ok2 := strings.Cut(spec, ":")
+	if !ok || !ok2 || step == "" || command == "" {
+		return generator.HookCommand{}, fmt.Errorf("want before:STEP=COMMAND or after:STEP=COMMAND")
+	}
+	switch generator.HookPhase(phase) {
+	case generator.HookBefore:
+		return generator.HookCommand{Before: step, Run: command}, nil
+	case generator.HookAfter:
+		return generator.HookCommand{After: step, Run: command}, nil
+	}
+	return generator.HookCommand{}, fmt.Errorf("unknown hook phase %q (want before or after)", phase)
+}
+
+// overrideConfig copies the flags explicitly set on the command line onto a
+// configuration loaded from a manifest.
+func overrideConfig(config *generator.Config, fs *flag.FlagSet) {
+	fs.Visit(func(f *flag.Flag) {
+		v := f.Value.String()
+		switch f.Name {
+		case "name":
+			config.Name = v
+		case "module":
+			config.Module = v
+		case "db":
+			config.DBDriver = v
+		case "sqlite-driver":
+			config.SQLiteDriver = v
+		case "port":
+			config.Port = v
+		case "auth":
+			config.WithAuth = v == "true"
+		case "users":
+			config.WithUsers = v == "true"
+		case "sessions":
+			config.WithSessions = v == "true"
+		}
+	})
+}
+
+// writeArchive generates the project into an archive file of the given
+// format, or to stdout when name is "-".
+func writeArchive(config *generator.Config, name string, format generator.ArchiveFormat, jsonOutput bool, opts ...generator.Option) int {
+	out := os.Stdout
+	if name != "-" {
+		f, err := os.Create(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitError
+		}
+		defer f.Close()
+		out = f
+	}
+
+	archive := generator.NewArchiveFS(out, format, config.Name)
+	gen := generator.New(config, append(opts, generator.WithFS(archive))...)
+	err := gen.Generate()
+	if err == nil {
+		err = archive.Close()
+	}
+	if err != nil {
+		if name != "-" {
+			// Do not leave a truncated archive behind.
+			out.Close()
+			os.Remove(name)
+		}
+		if jsonOutput {
+			printJSON(errorReport(err))
+		} else {
+			fmt.Fprintf(os.Stderr, "Error generating project: %v\n", err)
+		}
+		var stepErr *generator.UnknownStepError
+		if errors.As(err, &stepErr) {
+			return exitUsage
+		}
+		return exitError
+	}
+
+	if jsonOutput {
+		report := generationReport(gen, config, name, false)
+		report.NextSteps = []string{}
+		printJSON(report)
+	} else if name != "-" {
+		printWarnings(gen)
+		fmt.Printf("✅ Wrote project '%s' to %s\n", config.Name, name)
+	}
+	return exitOK
+}
+
+// printConflicts reports the existing files that differed from the generated
+// output and what was done with them.
+func printConflicts(gen *generator.Generator) {
+	for _, f := range gen.Files() {
+		switch f.Action {
+		case generator.ActionSkip:
+			fmt.Printf("⏭️  Kept existing %s\n", f.Path)
+		case generator.ActionOverwrite:
+			fmt.Printf("✏️  Overwrote %s\n", f.Path)
+		case generator.ActionBackup:
+			fmt.Printf("💾 Overwrote %s (previous version saved as %s.orig)\n", f.Path, f.Path)
+		case generator.ActionConflict:
+			fmt.Printf("⚠️  Kept existing %s; it differs from the generated version:\n%s\n", f.Path, f.Diff)
+		}
+	}
+}
+
+// printWarnings prints what the generator could not do for the project.
+func printWarnings(gen *generator.Generator) {
+	for _, w := range gen.Warnings() {
+		fmt.Printf("⚠️  %s\n", w)
+	}
+}
+
+// printPlan prints the directories and files a dry run would have created.
+func printPlan(gen *generator.Generator, output string, showContent bool) {
+	fmt.Printf("🔍 Dry run: nothing was written to %s\n\n", output)
+
+	fmt.Printf("Directories:\n")
+	for _, dir := range gen.Dirs() {
+		fmt.Printf("  %s/\n", dir)
+	}
+
+	var total int
+	fmt.Printf("\nFiles:\n")
+	for _, f := range gen.Files() {
+		fmt.Printf("  %-10s %-50s %8d bytes\n", f.Action, f.Path, len(f.Content))
+		total += len(f.Content)
+	}
+	fmt.Printf("\n%d files, %d bytes\n", len(gen.Files()), total)
+
+	for _, f := range gen.Files() {
+		if f.Diff != "" {
+			fmt.Printf("\n%s", f.Diff)
+		}
+	}
+
+	if !showContent {
+		return
+	}
+	for _, f := range gen.Files() {
+		fmt.Printf("\n==> %s <==\n%s", f.Path, f.Content)
+		if len(f.Content) > 0 && f.Content[len(f.Content)-1] != '\n' {
+			fmt.Println()
+		}
+	}
+}
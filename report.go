@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bennett-matt/goth-generator/generator"
+)
+
+// Output formats accepted by -output-format.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+func validOutputFormat(format string) bool {
+	return format == formatText || format == formatJSON
+}
+
+// newReport is what `goth-generate new -output-format json` prints: one JSON
+// object on stdout, on success and on failure.
+type newReport struct {
+	Config       *generator.Config       `json:"config,omitempty"`
+	Output       string                  `json:"output,omitempty"` // directory or archive written
+	DryRun       bool                    `json:"dry_run"`
+	Files        []fileReport            `json:"files"`
+	SkippedSteps []generator.SkippedStep `json:"skipped_steps"`
+	Warnings     []string                `json:"warnings"`
+	NextSteps    []string                `json:"next_steps"`
+
+	Error         string                  `json:"error,omitempty"`
+	InvalidFields []*generator.FieldError `json:"invalid_fields,omitempty"`
+	Conflicts     []string                `json:"conflicts,omitempty"` // files refused under -conflict refuse
+}
+
+type fileReport struct {
+	Path   string               `json:"path"`
+	Step   string               `json:"step"`
+	Action generator.FileAction `json:"action"`
+	SHA256 string               `json:"sha256"`
+	Size   int                  `json:"size"`
+	Diff   string               `json:"diff,omitempty"`
+}
+
+// generationReport describes a finished Generate run.
+func generationReport(gen *generator.Generator, config *generator.Config, output string, dryRun bool) *newReport {
+	r := &newReport{
+		Config:       config,
+		Output:       output,
+		DryRun:       dryRun,
+		Files:        []fileReport{},
+		SkippedSteps: append([]generator.SkippedStep{}, gen.Skipped()...),
+		Warnings:     append([]string{}, gen.Warnings()...),
+		NextSteps:    []string{},
+	}
+	for _, f := range gen.Files() {
+		r.Files = append(r.Files, fileReport{
+			Path:   f.Path,
+			Step:   f.Step,
+			Action: f.Action,
+			SHA256: generator.Sum(f.Content),
+			Size:   len(f.Content),
+			Diff:   f.Diff,
+		})
+		switch f.Action {
+		case generator.ActionSkip, generator.ActionConflict:
+			r.Warnings = append(r.Warnings, fmt.Sprintf("kept existing %s, which differs from the generated version", f.Path))
+		}
+	}
+	if !dryRun {
+		r.NextSteps = nextSteps(config, output)
+	}
+	return r
+}
+
+// errorReport describes a failed run, with the invalid fields or refused
+// files when err carries them.
+func errorReport(err error) *newReport {
+	r := &newReport{
+		Files:        []fileReport{},
+		SkippedSteps: []generator.SkippedStep{},
+		Warnings:     []string{},
+		NextSteps:    []string{},
+		Error:        err.Error(),
+	}
+	var verr *generator.ValidationError
+	if errors.As(err, &verr) {
+		r.InvalidFields = verr.Errors
+	}
+	var cerr *generator.ConflictError
+	if errors.As(err, &cerr) {
+		r.Conflicts = cerr.Paths
+	}
+	return r
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+}
+
+// nextSteps are the commands to run in a freshly generated project.
+func nextSteps(config *generator.Config, output string) []string {
+	steps := []string{"cd " + output, "make setup"}
+	if config.DBDriver != "sqlite" {
+		steps = append(steps, "docker-compose up -d db")
+	}
+	return append(steps, "make dev")
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bennett-matt/goth-generator/generator"
+)
+
+// runResource implements `goth-generate resource Name field:type...`.
+func runResource(args []string) int {
+	fs := newFlagSet("resource")
+	dir := fs.String("dir", ".", "Project directory")
+	dbDriver := fs.String("db", "postgres", "Database driver, for projects without "+generator.ManifestFile)
+	templates := fs.String("templates", "", "Directory of template overrides (resource templates live under resource/); defaults to the one recorded in "+generator.ManifestFile)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	res, err := generator.ParseResource(fs.Arg(0), fs.Args()[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	config, err := loadProjectConfig(*dir, *dbDriver)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v (run inside a generated project or pass -dir)\n", err)
+		return exitError
+	}
+
+	if *templates == "" {
+		*templates = config.Templates
+	}
+	layers, err := generator.TemplateLayers(*templates)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	packs, err := generator.LoadPacks(config.Packs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	gen := generator.New(config, generator.WithTemplates(layers...), generator.WithPacks(packs...))
+	if err := gen.GenerateResource(res); err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating resource: %v\n", err)
+		return exitError
+	}
+
+	pkg := strings.ToLower(res.Name)
+	fmt.Printf("✅ Generated resource '%s'\n", res.Name)
+	fmt.Printf("🚀 Next steps:\n")
+	fmt.Printf("   1. Register the routes in cmd/server/main.go:\n")
+	fmt.Printf("        handlers.New%sHandler(%q, %s.NewService(db)).Register(router)\n", res.Name, config.Name, pkg)
+	fmt.Printf("   2. make sqlc templ\n")
+	fmt.Printf("   3. make migrate-up\n")
+	return exitOK
+}
+
+// loadProjectConfig returns the configuration of the project in dir from its
+// manifest. Projects generated before manifests existed fall back to the
+// module in go.mod and the given database driver.
+func loadProjectConfig(dir, dbDriver string) (*generator.Config, error) {
+	m, err := generator.LoadManifest(dir)
+	if err == nil {
+		return &m.Config, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	module, err := readModulePath(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  No %s in %s; using go.mod and -db %s\n", generator.ManifestFile, dir, dbDriver)
+	return &generator.Config{
+		Name:      filepath.Base(module),
+		Module:    module,
+		OutputDir: dir,
+		DBDriver:  dbDriver,
+	}, nil
+}
+
+// readModulePath returns the module path declared in a go.mod file.
+func readModulePath(goModPath string) (string, error) {
+	f, err := os.Open(goModPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if module, ok := strings.CutPrefix(line, "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no module directive in %s", goModPath)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bennett-matt/goth-generator/generator"
+)
+
+// runStatus implements `goth-generate status`.
+func runStatus(args []string) int {
+	fs := newFlagSet("status")
+	dir := fs.String("dir", ".", "Project directory")
+	all := fs.Bool("all", false, "Also list untouched files")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	statuses, err := generator.Status(generator.DirFS(*dir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Error: no %s in %s (projects from older generator versions have no checksum ledger; run 'goth-generate upgrade' to create it, adding the -db, -auth, -users and -sessions the project was generated with if it has no %s)\n", generator.LedgerFile, *dir, generator.ManifestFile)
+			return exitError
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	counts := map[generator.FileState]int{}
+	for _, st := range statuses {
+		counts[st.State]++
+		if st.State != generator.StateUntouched || *all {
+			fmt.Printf("  %-9s %s\n", st.State, st.Path)
+		}
+	}
+	fmt.Printf("%d untouched, %d modified, %d deleted\n",
+		counts[generator.StateUntouched], counts[generator.StateModified], counts[generator.StateDeleted])
+	return exitOK
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bennett-matt/goth-generator/generator"
+	"golang.org/x/mod/modfile"
+)
+
+// runUpgrade implements `goth-generate upgrade`.
+func runUpgrade(args []string) int {
+	fs := newFlagSet("upgrade")
+	dir := fs.String("dir", ".", "Project directory")
+	base := fs.String("base", "", "Directory holding the pristine output of the recorded generator version (default: generate it with `go run`)")
+	dryRun := fs.Bool("dry-run", false, "Report what would change without writing anything")
+	// Projects generated before manifests existed are described with the
+	// flags they were generated with.
+	var (
+		from         = fs.String("from", preManifestVersion, "Without "+generator.ManifestFile+": the generator version the project was generated with")
+		name         = fs.String("name", "", "Without "+generator.ManifestFile+": the project name (default: last element of the module path)")
+		module       = fs.String("module", "", "Without "+generator.ManifestFile+": the module path (default: read from go.mod)")
+		dbDriver     = fs.String("db", "postgres", "Without "+generator.ManifestFile+": the database driver")
+		port         = fs.String("port", "8080", "Without "+generator.ManifestFile+": the server port")
+		withAuth     = fs.Bool("auth", true, "Without "+generator.ManifestFile+": whether authentication was included")
+		withUsers    = fs.Bool("users", true, "Without "+generator.ManifestFile+": whether user management was included")
+		withSessions = fs.Bool("sessions", true, "Without "+generator.ManifestFile+": whether session management was included")
+	)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	m, err := generator.LoadManifest(*dir)
+	if errors.Is(err, os.ErrNotExist) {
+		m, err = manifestFromFlags(*dir, generator.Config{
+			Name:             *name,
+			Module:           *module,
+			DBDriver:         *dbDriver,
+			Port:             *port,
+			WithAuth:         *withAuth,
+			WithUsers:        *withUsers,
+			WithSessions:     *withSessions,
+			GeneratorVersion: *from,
+		})
+		if err == nil {
+			fmt.Printf("📄 No %s in %s; upgrading from goth-generate %s with the configuration given as flags\n", generator.ManifestFile, *dir, *from)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+	if m.GeneratorVersion == generator.Version {
+		fmt.Printf("✅ Already generated by goth-generate %s\n", generator.Version)
+		return exitOK
+	}
+
+	var pristine map[string][]byte
+	if *base != "" {
+		pristine, err = readTree(*base)
+	} else {
+		fmt.Printf("📥 Generating pristine output of goth-generate %s...\n", m.GeneratorVersion)
+		pristine, err = fetchPristine(m.Config)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	results, err := generator.Upgrade(generator.DirFS(*dir), m, pristine, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error upgrading project: %v\n", err)
+		return exitError
+	}
+
+	conflicts := 0
+	for _, r := range results {
+		switch r.Status {
+		case generator.UpgradeUnchanged, generator.UpgradeKept:
+			continue
+		case generator.UpgradeConflict:
+			conflicts++
+			fmt.Printf("  %-9s %s (%d conflicting region(s))\n", r.Status, r.Path, r.Conflicts)
+		case generator.UpgradeSkipped:
+			fmt.Printf("  %-9s %s (%s)\n", r.Status, r.Path, r.Note)
+		default:
+			fmt.Printf("  %-9s %s\n", r.Status, r.Path)
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("🔍 Dry run: nothing was written (%d file(s) would conflict)\n", conflicts)
+		return exitOK
+	}
+	if conflicts > 0 {
+		fmt.Printf("⚠️  %d file(s) have conflict markers (<<<<<<< project ... >>>>>>> goth-generate %s); resolve them and commit\n", conflicts, generator.Version)
+		return exitError
+	}
+	fmt.Printf("✅ Upgraded from goth-generate %s to %s\n", m.GeneratorVersion, generator.Version)
+	return exitOK
+}
+
+// preManifestVersion is the last generator version that wrote no manifest.
+const preManifestVersion = "1.1.0"
+
+// manifestFromFlags describes a project generated before manifests existed
+// by config, filling in the module path and Go version from its go.mod.
+func manifestFromFlags(dir string, config generator.Config) (*generator.Manifest, error) {
+	goModPath := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, fmt.Errorf("%v (upgrade needs the project's %s or go.mod)", err, generator.ManifestFile)
+	}
+	f, err := modfile.ParseLax(goModPath, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	if config.Module == "" && f.Module != nil {
+		config.Module = f.Module.Mod.Path
+	}
+	if config.Module == "" {
+		return nil, fmt.Errorf("no module directive in %s; pass -module", goModPath)
+	}
+	if config.Name == "" {
+		config.Name = path.Base(config.Module)
+	}
+	if f.Go != nil {
+		// go.mod may name a patch release; the manifest records major.minor.
+		major, rest, _ := strings.Cut(f.Go.Version, ".")
+		minor, _, _ := strings.Cut(rest, ".")
+		config.GoVersion = major + "." + minor
+	}
+	config.OutputDir = dir
+	if ferr := generator.ValidateDBDriver(config.DBDriver); ferr != nil {
+		return nil, ferr
+	}
+	return &generator.Manifest{Config: config}, nil
+}
+
+// fetchPristine regenerates a project with the generator version recorded
+// in its manifest and returns the output. It passes the flags every release
+// understands, and the later ones only when the project uses them, since
+// the release that generated it did too.
+func fetchPristine(config generator.Config) (map[string][]byte, error) {
+	tmp, err := os.MkdirTemp("", "goth-upgrade-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	out := filepath.Join(tmp, "project")
+	args := []string{"run", "github.com/bennett-matt/goth-generator@v" + config.GeneratorVersion,
+		"-name", config.Name,
+		"-module", config.Module,
+		"-output", out,
+		"-db", config.DBDriver,
+		"-port", config.Port,
+		fmt.Sprintf("-auth=%t", config.WithAuth),
+		fmt.Sprintf("-users=%t", config.WithUsers),
+		fmt.Sprintf("-sessions=%t", config.WithSessions),
+	}
+	if config.SQLiteDriver != "" {
+		args = append(args, "-sqlite-driver", config.SQLiteDriver)
+	}
+	if config.Templates != "" {
+		args = append(args, "-templates", config.Templates)
+	}
+	for _, p := range config.Packs {
+		args = append(args, "-pack", p)
+	}
+	for _, k := range slices.Sorted(maps.Keys(config.Extra)) {
+		args = append(args, "-set", fmt.Sprintf("%s=%v", k, config.Extra[k]))
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = tmp
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("goth-generate %s: %w: %s (pass -base to use a local copy)", config.GeneratorVersion, err, output)
+	}
+	return readTree(out)
+}
+
+// readTree reads every file below dir, keyed by slash-separated relative path.
+func readTree(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
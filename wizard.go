@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/bennett-matt/goth-generator/generator"
+	"golang.org/x/term"
+)
+
+// errAborted is returned by the wizard when the summary is not confirmed.
+var errAborted = errors.New("aborted; nothing was written")
+
+// isTerminal reports whether f is an interactive terminal rather than a
+// pipe, file or /dev/null.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// prompter asks questions on a terminal, one answer per line.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints question with its default and returns the answer, or def when
+// the answer is empty.
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			fmt.Fprintln(p.out)
+			return "", errAborted
+		}
+		return "", err
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// askValid asks until valid accepts the answer, printing its error otherwise.
+func (p *prompter) askValid(question, def string, valid func(string) error) (string, error) {
+	for {
+		answer, err := p.ask(question, def)
+		if err != nil {
+			return "", err
+		}
+		if err := valid(answer); err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// confirm asks a yes/no question.
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	answer, err := p.askValid(question, hint, func(s string) error {
+		if s == hint {
+			return nil
+		}
+		switch strings.ToLower(s) {
+		case "y", "yes", "n", "no":
+			return nil
+		}
+		return fmt.Errorf("answer y or n")
+	})
+	if err != nil || answer == hint {
+		return def, err
+	}
+	return strings.HasPrefix(strings.ToLower(answer), "y"), nil
+}
+
+// runWizard fills in config interactively. Settings given as flags are not
+// asked again; the others start from the flag defaults. It returns
+// errAborted if the summary is not confirmed.
+func runWizard(config *generator.Config, fs *flag.FlagSet, in io.Reader, out io.Writer) error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	p := &prompter{in: bufio.NewReader(in), out: out}
+	fmt.Fprintf(out, "Let's set up a new project. Press Enter to accept the value in brackets.\n\n")
+
+	var err error
+	if config.Name, err = p.askValid("Project name", "", valid(generator.ValidateName)); err != nil {
+		return err
+	}
+	if !set["module"] {
+		if config.Module, err = p.askValid("Go module path", suggestModule(config.Name), validModulePath); err != nil {
+			return err
+		}
+	}
+	if !set["db"] {
+		if config.DBDriver, err = p.askValid("Database ("+strings.Join(generator.DBDrivers, ", ")+")", config.DBDriver, valid(generator.ValidateDBDriver)); err != nil {
+			return err
+		}
+	}
+	if config.DBDriver == "sqlite" && !set["sqlite-driver"] {
+		def := config.SQLiteDriver
+		if def == "" {
+			def = generator.SQLiteDrivers[0]
+		}
+		if config.SQLiteDriver, err = p.askValid("SQLite driver (mattn needs CGO, modernc is pure Go)", def, validSQLiteDriver); err != nil {
+			return err
+		}
+	}
+	if !set["port"] {
+		if config.Port, err = p.askValid("Server port", config.Port, valid(generator.ValidatePort)); err != nil {
+			return err
+		}
+	}
+	for _, feature := range []struct {
+		flag     string
+		question string
+		value    *bool
+	}{
+		{"auth", "Include authentication (login, register)?", &config.WithAuth},
+		{"users", "Include user management?", &config.WithUsers},
+		{"sessions", "Include session management?", &config.WithSessions},
+	} {
+		if set[feature.flag] {
+			continue
+		}
+		if *feature.value, err = p.confirm(feature.question, *feature.value); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "\nSummary:\n")
+	fmt.Fprintf(out, "  Name:      %s\n", config.Name)
+	fmt.Fprintf(out, "  Module:    %s\n", normalizeModulePath(config.Module))
+	fmt.Fprintf(out, "  Directory: %s\n", config.OutputDir)
+	if config.DBDriver == "sqlite" {
+		fmt.Fprintf(out, "  Database:  %s (%s)\n", config.DBDriver, config.SQLiteDriver)
+	} else {
+		fmt.Fprintf(out, "  Database:  %s\n", config.DBDriver)
+	}
+	fmt.Fprintf(out, "  Port:      %s\n", config.Port)
+	fmt.Fprintf(out, "  Auth:      %s\n", yesNo(config.WithAuth))
+	fmt.Fprintf(out, "  Users:     %s\n", yesNo(config.WithUsers))
+	fmt.Fprintf(out, "  Sessions:  %s\n\n", yesNo(config.WithSessions))
+
+	ok, err := p.confirm("Generate the project?", true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errAborted
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// suggestModule derives a module path for name from the GitHub user in the
+// git configuration, falling back to the bare name.
+func suggestModule(name string) string {
+	name = strings.ToLower(name)
+	out, err := exec.Command("git", "config", "--get", "github.user").Output()
+	if user := strings.TrimSpace(string(out)); err == nil && user != "" {
+		return "github.com/" + user + "/" + name
+	}
+	return name
+}
+
+// valid adapts a generator field validator to the prompter, reporting just
+// the reason the answer was rejected.
+func valid(validate func(string) *generator.FieldError) func(string) error {
+	return func(s string) error {
+		if err := validate(s); err != nil {
+			return errors.New(err.Reason)
+		}
+		return nil
+	}
+}
+
+func validModulePath(s string) error {
+	return valid(generator.ValidateModule)(normalizeModulePath(s))
+}
+
+func validSQLiteDriver(s string) error {
+	if err := generator.ValidateSQLiteDriver("sqlite", s); err != nil {
+		return errors.New(err.Reason)
+	}
+	return nil
+}